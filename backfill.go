@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func runBackfill(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file (default ~/.overmind/config.yaml)")
+	days := fs.Int("days", 30, "number of UTC days to backfill, including today")
+	timeout := fs.Duration("timeout", 2*time.Minute, "maximum time to wait for PostHog")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: overmind backfill [flags]\n\nLoad true per-day visits and uniques from PostHog into the store.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return fmt.Errorf("days must be positive, got %d", *days)
+	}
+
+	return withApp(*configPath, func(a *app) error {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		written, err := a.fetcher.Backfill(ctx, a.products, *days)
+		for _, p := range a.products {
+			if n, ok := written[p.Name]; ok {
+				fmt.Fprintf(os.Stdout, "%s: %d days\n", p.Name, n)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(written) == 0 {
+			fmt.Fprintln(os.Stdout, "No products have a posthog host_filter; nothing to backfill.")
+		}
+		return nil
+	})
+}
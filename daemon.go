@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file (default ~/.overmind/config.yaml)")
+	interval := fs.Duration("interval", 0, "time between snapshots (default daemon.interval from config, or 15m)")
+	timeout := fs.Duration("timeout", 30*time.Second, "maximum time to wait for all providers in one cycle")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: overmind daemon [flags]\n\nFetch metrics on a schedule and write snapshots to the store until SIGINT/SIGTERM.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *interval < 0 {
+		return fmt.Errorf("interval must be positive, got %s", *interval)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := log.New(os.Stderr, "overmind: ", log.LstdFlags)
+
+	return withApp(*configPath, func(a *app) error {
+		every := *interval
+		if every == 0 {
+			every = a.cfg.Daemon.Every()
+		}
+
+		logger.Printf("daemon started: %d products, interval %s", len(a.products), every)
+		runDaemonLoop(ctx, every, func() {
+			// Cycles are detached from the signal context so an in-flight
+			// snapshot finishes writing before shutdown.
+			cycleCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+			defer cancel()
+
+			start := time.Now()
+			metrics := a.fetcher.FetchAll(cycleCtx, a.products)
+			failed := 0
+			for _, p := range a.products {
+				m := metrics[p.Name]
+				if m == nil {
+					failed++
+					logger.Printf("%s: no metrics returned", p.Name)
+					continue
+				}
+				if len(m.Errors) > 0 {
+					failed++
+					for _, msg := range m.Errors {
+						logger.Printf("%s: %s", p.Name, msg)
+					}
+				}
+			}
+			logger.Printf("cycle complete: %d products, %d with errors, took %s",
+				len(a.products), failed, time.Since(start).Round(time.Millisecond))
+		})
+		logger.Printf("daemon stopped")
+		return nil
+	})
+}
+
+// runDaemonLoop runs cycle immediately and then once per interval until ctx is
+// cancelled. A cycle in progress always runs to completion.
+func runDaemonLoop(ctx context.Context, interval time.Duration, cycle func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		cycle()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunDaemonLoop(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "runs immediately then on interval until cancelled",
+			fn: func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				cycles := 0
+				done := make(chan struct{})
+				go func() {
+					runDaemonLoop(ctx, time.Millisecond, func() {
+						cycles++
+						if cycles == 3 {
+							cancel()
+						}
+					})
+					close(done)
+				}()
+
+				select {
+				case <-done:
+				case <-time.After(2 * time.Second):
+					t.Fatalf("runDaemonLoop did not stop after cancel")
+				}
+				if cycles != 3 {
+					t.Fatalf("cycles = %d, want 3", cycles)
+				}
+			},
+		},
+		{
+			name: "does not run when already cancelled",
+			fn: func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				cycles := 0
+				runDaemonLoop(ctx, time.Hour, func() { cycles++ })
+				if cycles != 0 {
+					t.Fatalf("cycles = %d, want 0", cycles)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/report"
+)
+
+func runIncidents(args []string) error {
+	fs := flag.NewFlagSet("incidents", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file (default ~/.overmind/config.yaml)")
+	format := fs.String("format", string(report.FormatTable), "output format: table, json or csv")
+	days := fs.Int("days", 30, "show incidents ongoing at any point in the last N days")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: overmind incidents [flags]\n\nList outages and degradations recorded from health checks, newest first.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return fmt.Errorf("days must be positive, got %d", *days)
+	}
+
+	outFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	return withApp(*configPath, func(a *app) error {
+		now := time.Now()
+		incidents, err := a.fetcher.Incidents(context.Background(), a.products, now.AddDate(0, 0, -*days))
+		if err != nil {
+			return err
+		}
+		return report.WriteIncidents(os.Stdout, outFormat, report.IncidentRows(incidents, now))
+	})
+}
@@ -3,9 +3,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -15,6 +19,25 @@ import (
 type Config struct {
 	Products    []ProductConfig   `yaml:"products"`
 	Credentials CredentialsConfig `yaml:"credentials"`
+	Daemon      DaemonConfig      `yaml:"daemon,omitempty"`
+
+	// Plugins configures external-executable providers by name. A product
+	// may also use a plugin with no entry here if overmind-provider-<name>
+	// is on PATH.
+	Plugins map[string]PluginConfig `yaml:"plugins,omitempty"`
+}
+
+type PluginConfig struct {
+	Command []string       `yaml:"command,omitempty"` // argv; defaults to overmind-provider-<name>
+	Timeout time.Duration  `yaml:"timeout,omitempty"` // per product run, e.g. "10s"
+	Columns []ColumnConfig `yaml:"columns,omitempty"` // dashboard columns for the plugin's metrics
+}
+
+type ColumnConfig struct {
+	Key    string `yaml:"key"`              // metric key as the plugin reports it, e.g. "depth"
+	Title  string `yaml:"title"`            // header, e.g. "QUEUE"
+	Format string `yaml:"format,omitempty"` // number (default), currency, percent, ms or text
+	Width  int    `yaml:"width,omitempty"`
 }
 
 type ProductConfig struct {
@@ -22,6 +45,18 @@ type ProductConfig struct {
 	Domain  string        `yaml:"domain"`
 	Stripe  StripeConfig  `yaml:"stripe,omitempty"`
 	PostHog PostHogConfig `yaml:"posthog,omitempty"`
+	Sentry  SentryConfig  `yaml:"sentry,omitempty"`
+
+	VercelProjectID string `yaml:"vercel_project_id,omitempty"` // e.g., "prj_xxx"
+	GitHubRepo      string `yaml:"github_repo,omitempty"`       // e.g., "misty-step/chrondle"
+
+	// Health customizes the health check; unset checks GET https://<domain>/.
+	Health *HealthConfig `yaml:"health,omitempty"`
+
+	// Providers configures registered providers without a dedicated key,
+	// keyed by provider name. String values, nested ones included, support
+	// ${VAR} expansion.
+	Providers map[string]map[string]any `yaml:"providers,omitempty"`
 }
 
 type StripeConfig struct {
@@ -32,9 +67,56 @@ type PostHogConfig struct {
 	HostFilter string `yaml:"host_filter"` // e.g., "chrondle.app"
 }
 
+type HealthConfig struct {
+	Path         string             `yaml:"path,omitempty"`          // e.g., "/api/health"; defaults to "/"
+	Method       string             `yaml:"method,omitempty"`        // GET (default) or HEAD
+	Headers      map[string]string  `yaml:"headers,omitempty"`       // values support ${VAR}
+	ExpectStatus []int              `yaml:"expect_status,omitempty"` // e.g., [200]; defaults to any 2xx/3xx
+	BodyContains string             `yaml:"body_contains,omitempty"`
+	BodyMatches  string             `yaml:"body_matches,omitempty"` // regular expression
+	JSON         []JSONAssertConfig `yaml:"json,omitempty"`
+	DegradedOver time.Duration      `yaml:"degraded_over,omitempty"`  // median latency, e.g., "800ms"
+	Timeout      time.Duration      `yaml:"timeout,omitempty"`        // per request; defaults to 5s
+	Retries      int                `yaml:"retries,omitempty"`        // extra attempts when a probe finds the site down
+	Probes       int                `yaml:"probes,omitempty"`         // requests per check; defaults to 1
+	DownAfter    int                `yaml:"down_after,omitempty"`     // consecutive failed checks before down; defaults to 1
+	CertWarnDays int                `yaml:"cert_warn_days,omitempty"` // warn when the certificate expires sooner; defaults to 14
+
+	bodyMatches *regexp.Regexp // BodyMatches, compiled by validateHealth
+}
+
+type JSONAssertConfig struct {
+	Path   string `yaml:"path"`   // e.g., "$.checks.db"
+	Equals any    `yaml:"equals"` // e.g., "ok", true or 1
+}
+
+type DaemonConfig struct {
+	Interval time.Duration `yaml:"interval"` // e.g., "15m"; defaults to DefaultDaemonInterval
+}
+
+// DefaultDaemonInterval is how often `overmind daemon` snapshots metrics when
+// no interval is configured.
+const DefaultDaemonInterval = 15 * time.Minute
+
+// Every returns how often `overmind daemon` snapshots metrics: Interval, or
+// DefaultDaemonInterval when none is configured.
+func (d DaemonConfig) Every() time.Duration {
+	if d.Interval == 0 {
+		return DefaultDaemonInterval
+	}
+	return d.Interval
+}
+
+type SentryConfig struct {
+	Project string `yaml:"project"` // project slug, e.g., "chrondle"
+}
+
 type CredentialsConfig struct {
 	Stripe  StripeCredentials  `yaml:"stripe"`
 	PostHog PostHogCredentials `yaml:"posthog"`
+	Sentry  SentryCredentials  `yaml:"sentry"`
+	Vercel  VercelCredentials  `yaml:"vercel"`
+	GitHub  GitHubCredentials  `yaml:"github"`
 }
 
 type StripeCredentials struct {
@@ -47,6 +129,21 @@ type PostHogCredentials struct {
 	Host      string `yaml:"host"`       // "https://us.i.posthog.com"
 }
 
+type SentryCredentials struct {
+	AuthToken string `yaml:"auth_token"` // ${SENTRY_AUTH_TOKEN}
+	Org       string `yaml:"org"`        // organization slug
+	Host      string `yaml:"host"`       // "https://sentry.io"
+}
+
+type VercelCredentials struct {
+	Token  string `yaml:"token"`   // ${VERCEL_TOKEN}
+	TeamID string `yaml:"team_id"` // optional, for team-owned projects
+}
+
+type GitHubCredentials struct {
+	Token string `yaml:"token"` // ${GITHUB_TOKEN}; optional but raises rate limits
+}
+
 func DefaultConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -80,6 +177,24 @@ func Load(path string) (*Config, error) {
 	cfg.Credentials.PostHog.APIKey = expandEnvValue(cfg.Credentials.PostHog.APIKey)
 	cfg.Credentials.PostHog.ProjectID = expandEnvValue(cfg.Credentials.PostHog.ProjectID)
 	cfg.Credentials.PostHog.Host = expandEnvValue(cfg.Credentials.PostHog.Host)
+	cfg.Credentials.Sentry.AuthToken = expandEnvValue(cfg.Credentials.Sentry.AuthToken)
+	cfg.Credentials.Sentry.Org = expandEnvValue(cfg.Credentials.Sentry.Org)
+	cfg.Credentials.Sentry.Host = expandEnvValue(cfg.Credentials.Sentry.Host)
+	cfg.Credentials.Vercel.Token = expandEnvValue(cfg.Credentials.Vercel.Token)
+	cfg.Credentials.Vercel.TeamID = expandEnvValue(cfg.Credentials.Vercel.TeamID)
+	cfg.Credentials.GitHub.Token = expandEnvValue(cfg.Credentials.GitHub.Token)
+	for _, product := range cfg.Products {
+		if h := product.Health; h != nil {
+			for key, value := range h.Headers {
+				h.Headers[key] = expandEnvValue(value)
+			}
+		}
+	}
+	for _, plugin := range cfg.Plugins {
+		for i, arg := range plugin.Command {
+			plugin.Command[i] = expandEnvValue(arg)
+		}
+	}
 
 	if err := validateConfig(&cfg); err != nil {
 		return nil, err
@@ -96,15 +211,81 @@ func (c *Config) ToProducts() []domain.Product {
 	products := make([]domain.Product, 0, len(c.Products))
 	for _, p := range c.Products {
 		products = append(products, domain.Product{
-			Name:        p.Name,
-			Domain:      p.Domain,
-			StripeID:    p.Stripe.ProductID,
-			PostHogHost: p.PostHog.HostFilter,
+			Name:            p.Name,
+			Domain:          p.Domain,
+			StripeID:        p.Stripe.ProductID,
+			PostHogHost:     p.PostHog.HostFilter,
+			SentryProject:   p.Sentry.Project,
+			VercelProjectID: p.VercelProjectID,
+			GitHubRepo:      p.GitHubRepo,
+			Health:          p.Health.check(),
+			Providers:       providerConfigs(p.Providers),
 		})
 	}
 	return products
 }
 
+func (c *HealthConfig) check() *domain.HealthCheck {
+	if c == nil {
+		return nil
+	}
+	check := &domain.HealthCheck{
+		Path:         c.Path,
+		Method:       strings.ToUpper(c.Method),
+		Headers:      maps.Clone(c.Headers),
+		ExpectStatus: slices.Clone(c.ExpectStatus),
+		BodyContains: c.BodyContains,
+		BodyMatches:  c.bodyMatches,
+		DegradedOver: c.DegradedOver,
+		Timeout:      c.Timeout,
+		Retries:      c.Retries,
+		Probes:       c.Probes,
+		DownAfter:    c.DownAfter,
+		CertWarnDays: c.CertWarnDays,
+	}
+	for _, a := range c.JSON {
+		check.JSON = append(check.JSON, domain.JSONAssert{Path: a.Path, Equals: a.Equals})
+	}
+	return check
+}
+
+func providerConfigs(raw map[string]map[string]any) map[string]domain.ProviderConfig {
+	if len(raw) == 0 {
+		return nil
+	}
+	configs := make(map[string]domain.ProviderConfig, len(raw))
+	for name, settings := range raw {
+		cfg := make(domain.ProviderConfig, len(settings))
+		for key, value := range settings {
+			cfg[key] = expandEnvSetting(value)
+		}
+		configs[name] = cfg
+	}
+	return configs
+}
+
+// expandEnvSetting expands ${VAR} in a provider setting's string values,
+// including those nested in maps and lists such as request headers.
+func expandEnvSetting(value any) any {
+	switch v := value.(type) {
+	case string:
+		return expandEnvValue(v)
+	case map[string]any:
+		expanded := make(map[string]any, len(v))
+		for key, item := range v {
+			expanded[key] = expandEnvSetting(item)
+		}
+		return expanded
+	case []any:
+		expanded := make([]any, len(v))
+		for i, item := range v {
+			expanded[i] = expandEnvSetting(item)
+		}
+		return expanded
+	}
+	return value
+}
+
 func expandEnvValue(value string) string {
 	if strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}") && len(value) > 3 {
 		key := strings.TrimSuffix(strings.TrimPrefix(value, "${"), "}")
@@ -125,11 +306,82 @@ func validateConfig(cfg *Config) error {
 		if product.Domain == "" {
 			return fmt.Errorf("config: product %q missing domain", product.Name)
 		}
+		if product.GitHubRepo != "" {
+			owner, repo, ok := strings.Cut(product.GitHubRepo, "/")
+			if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+				return fmt.Errorf("config: product %q github_repo %q must be owner/name", product.Name, product.GitHubRepo)
+			}
+		}
+		if err := validateHealth(product.Health); err != nil {
+			return fmt.Errorf("config: product %q health: %w", product.Name, err)
+		}
+	}
+
+	if cfg.Daemon.Interval < 0 {
+		return fmt.Errorf("config: daemon interval must be positive, got %s", cfg.Daemon.Interval)
+	}
+
+	for name, plugin := range cfg.Plugins {
+		if plugin.Timeout < 0 {
+			return fmt.Errorf("config: plugin %q timeout must be positive, got %s", name, plugin.Timeout)
+		}
+		for i, col := range plugin.Columns {
+			if col.Key == "" || col.Title == "" {
+				return fmt.Errorf("config: plugin %q columns[%d] needs key and title", name, i)
+			}
+		}
 	}
 
 	return nil
 }
 
+func validateHealth(h *HealthConfig) error {
+	if h == nil {
+		return nil
+	}
+	if h.Path != "" && !strings.HasPrefix(h.Path, "/") {
+		return fmt.Errorf("path %q must start with /", h.Path)
+	}
+	switch strings.ToUpper(h.Method) {
+	case "", "GET":
+	case "HEAD":
+		if h.BodyContains != "" || h.BodyMatches != "" || len(h.JSON) > 0 {
+			return errors.New("body assertions need method GET")
+		}
+	default:
+		return fmt.Errorf("method %q must be GET or HEAD", h.Method)
+	}
+	for _, status := range h.ExpectStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("expect_status %d is not an HTTP status", status)
+		}
+	}
+	if h.BodyMatches != "" {
+		re, err := regexp.Compile(h.BodyMatches)
+		if err != nil {
+			return fmt.Errorf("body_matches: %w", err)
+		}
+		h.bodyMatches = re
+	}
+	for i, a := range h.JSON {
+		if a.Path == "" {
+			return fmt.Errorf("json[%d] missing path", i)
+		}
+		switch a.Equals.(type) {
+		case nil, string, bool, int, uint64, float64:
+		default:
+			return fmt.Errorf("json[%d] equals must be a string, number, boolean or null", i)
+		}
+	}
+	if h.DegradedOver < 0 || h.Timeout < 0 {
+		return errors.New("degraded_over and timeout must be positive")
+	}
+	if h.Retries < 0 || h.Probes < 0 || h.DownAfter < 0 || h.CertWarnDays < 0 {
+		return errors.New("retries, probes, down_after and cert_warn_days must be positive")
+	}
+	return nil
+}
+
 func validateCredentials(cfg *Config) error {
 	var errs []string
 
@@ -147,6 +399,20 @@ func validateCredentials(cfg *Config) error {
 		// Note: posthog host is optional; client defaults to https://us.i.posthog.com
 	}
 
+	if hasSentryProduct(cfg) {
+		if strings.TrimSpace(cfg.Credentials.Sentry.AuthToken) == "" {
+			errs = append(errs, "missing sentry auth_token; required because a product has sentry project")
+		}
+		if strings.TrimSpace(cfg.Credentials.Sentry.Org) == "" {
+			errs = append(errs, "missing sentry org; required because a product has sentry project")
+		}
+		// Note: sentry host is optional; client defaults to https://sentry.io
+	}
+
+	if hasVercelProduct(cfg) && strings.TrimSpace(cfg.Credentials.Vercel.Token) == "" {
+		errs = append(errs, "missing vercel token; required because a product has vercel_project_id")
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("config: %s", strings.Join(errs, "; "))
 	}
@@ -170,3 +436,21 @@ func hasPostHogProduct(cfg *Config) bool {
 	}
 	return false
 }
+
+func hasSentryProduct(cfg *Config) bool {
+	for _, p := range cfg.Products {
+		if p.Sentry.Project != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVercelProduct(cfg *Config) bool {
+	for _, p := range cfg.Products {
+		if p.VercelProjectID != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/phaedrus/overmind/internal/domain"
 )
@@ -59,6 +63,72 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: `product "App" missing domain`,
 		},
+		{
+			name: "malformed github repo",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com", GitHubRepo: "app"}},
+			},
+			wantErr: `github_repo "app" must be owner/name`,
+		},
+		{
+			name: "negative daemon interval",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com"}},
+				Daemon:   DaemonConfig{Interval: -time.Minute},
+			},
+			wantErr: "daemon interval must be positive",
+		},
+		{
+			name: "health path without slash",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com", Health: &HealthConfig{Path: "healthz"}}},
+			},
+			wantErr: `product "App" health: path "healthz" must start with /`,
+		},
+		{
+			name: "health HEAD with body assertion",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com", Health: &HealthConfig{Method: "head", BodyContains: "ok"}}},
+			},
+			wantErr: "body assertions need method GET",
+		},
+		{
+			name: "health bad regex",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com", Health: &HealthConfig{BodyMatches: "("}}},
+			},
+			wantErr: "body_matches",
+		},
+		{
+			name: "health bad status",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com", Health: &HealthConfig{ExpectStatus: []int{20}}}},
+			},
+			wantErr: "expect_status 20 is not an HTTP status",
+		},
+		{
+			name: "health negative retries",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com", Health: &HealthConfig{Retries: -1}}},
+			},
+			wantErr: "retries, probes, down_after and cert_warn_days must be positive",
+		},
+		{
+			name: "negative plugin timeout",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com"}},
+				Plugins:  map[string]PluginConfig{"queue": {Timeout: -time.Second}},
+			},
+			wantErr: `plugin "queue" timeout must be positive`,
+		},
+		{
+			name: "plugin column without key",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "example.com"}},
+				Plugins:  map[string]PluginConfig{"queue": {Columns: []ColumnConfig{{Title: "QUEUE"}}}},
+			},
+			wantErr: `plugin "queue" columns[0] needs key and title`,
+		},
 		{
 			name: "valid",
 			cfg: Config{
@@ -112,6 +182,20 @@ func TestValidateCredentials(t *testing.T) {
 			},
 			wantErrs: []string{"missing posthog api_key; required because a product has posthog host_filter", "missing posthog project_id; required because a product has posthog host_filter"},
 		},
+		{
+			name: "sentry product requires auth token and org",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "app.com", Sentry: SentryConfig{Project: "app"}}},
+			},
+			wantErrs: []string{"missing sentry auth_token; required because a product has sentry project", "missing sentry org; required because a product has sentry project"},
+		},
+		{
+			name: "vercel product requires token",
+			cfg: Config{
+				Products: []ProductConfig{{Name: "App", Domain: "app.com", VercelProjectID: "prj_1"}},
+			},
+			wantErrs: []string{"missing vercel token; required because a product has vercel_project_id"},
+		},
 		{
 			name: "stripe and posthog with creds ok",
 			cfg: Config{
@@ -162,6 +246,9 @@ func TestToProducts(t *testing.T) {
 				PostHog: PostHogConfig{
 					HostFilter: "app.com",
 				},
+				Sentry:          SentryConfig{Project: "app"},
+				VercelProjectID: "prj_1",
+				GitHubRepo:      "acme/app",
 			},
 			{
 				Name:   "Tool",
@@ -173,10 +260,13 @@ func TestToProducts(t *testing.T) {
 	got := cfg.ToProducts()
 	want := []domain.Product{
 		{
-			Name:        "App",
-			Domain:      "app.com",
-			StripeID:    "prod_1",
-			PostHogHost: "app.com",
+			Name:            "App",
+			Domain:          "app.com",
+			StripeID:        "prod_1",
+			PostHogHost:     "app.com",
+			SentryProject:   "app",
+			VercelProjectID: "prj_1",
+			GitHubRepo:      "acme/app",
 		},
 		{
 			Name:   "Tool",
@@ -188,3 +278,146 @@ func TestToProducts(t *testing.T) {
 		t.Fatalf("ToProducts() = %#v, want %#v", got, want)
 	}
 }
+
+func TestLoadDaemonInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte("products:\n  - name: App\n    domain: app.com\ndaemon:\n  interval: 5m\n")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Daemon.Interval != 5*time.Minute {
+		t.Fatalf("Daemon.Interval = %s, want 5m", cfg.Daemon.Interval)
+	}
+	if got := cfg.Daemon.Every(); got != 5*time.Minute {
+		t.Fatalf("Daemon.Every() = %s, want 5m", got)
+	}
+	if got := (DaemonConfig{}).Every(); got != DefaultDaemonInterval {
+		t.Fatalf("DaemonConfig{}.Every() = %s, want %s", got, DefaultDaemonInterval)
+	}
+}
+
+func TestLoadProviderConfig(t *testing.T) {
+	t.Setenv("PLAUSIBLE_TOKEN", "secret")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`products:
+  - name: App
+    domain: app.com
+    providers:
+      plausible:
+        site_id: app.com
+        token: ${PLAUSIBLE_TOKEN}
+        goals: 3
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got := cfg.ToProducts()[0].Providers
+	want := map[string]domain.ProviderConfig{
+		"plausible": {"site_id": "app.com", "token": "secret", "goals": 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Providers = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadNestedProviderConfig(t *testing.T) {
+	t.Setenv("STATS_TOKEN", "secret")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`products:
+  - name: App
+    domain: app.com
+    providers:
+      http:
+        url: https://app.com/api/stats
+        bearer_token: ${STATS_TOKEN}
+        headers:
+          X-Team: ${STATS_TOKEN}
+        metrics:
+          - name: signups
+            path: $.data.signups
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got := cfg.ToProducts()[0].Providers
+	want := map[string]domain.ProviderConfig{
+		"http": {
+			"url":          "https://app.com/api/stats",
+			"bearer_token": "secret",
+			"headers":      map[string]any{"X-Team": "secret"},
+			"metrics":      []any{map[string]any{"name": "signups", "path": "$.data.signups"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Providers = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadHealthConfig(t *testing.T) {
+	t.Setenv("HEALTH_TOKEN", "secret")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`products:
+  - name: App
+    domain: app.com
+    health:
+      path: /api/health
+      headers:
+        Authorization: ${HEALTH_TOKEN}
+      expect_status: [200]
+      body_contains: ok
+      body_matches: build \d+
+      json:
+        - path: $.checks.db
+          equals: up
+        - path: $.workers
+          equals: 3
+      degraded_over: 800ms
+      retries: 2
+      probes: 3
+      down_after: 2
+      cert_warn_days: 30
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got := cfg.ToProducts()[0].Health
+	want := &domain.HealthCheck{
+		Path:         "/api/health",
+		Headers:      map[string]string{"Authorization": "secret"},
+		ExpectStatus: []int{200},
+		BodyContains: "ok",
+		BodyMatches:  regexp.MustCompile(`build \d+`),
+		JSON: []domain.JSONAssert{
+			{Path: "$.checks.db", Equals: "up"},
+			{Path: "$.workers", Equals: 3},
+		},
+		DegradedOver: 800 * time.Millisecond,
+		Retries:      2,
+		Probes:       3,
+		DownAfter:    2,
+		CertWarnDays: 30,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Health = %#v, want %#v", got, want)
+	}
+}
@@ -1,33 +1,295 @@
 package domain
 
-import "time"
+import (
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"time"
+)
 
 type Product struct {
-	Name        string
-	Domain      string
-	StripeID    string // Stripe product ID
-	PostHogHost string // PostHog host filter for analytics
+	Name            string
+	Domain          string
+	StripeID        string       // Stripe product ID
+	PostHogHost     string       // PostHog host filter for analytics
+	SentryProject   string       // Sentry project slug
+	VercelProjectID string       // Vercel project ID
+	GitHubRepo      string       // GitHub repository, "owner/name"
+	Health          *HealthCheck // nil checks GET https://<domain>/ by status range
+
+	// Providers holds per-product settings for registered providers without
+	// a dedicated field, keyed by provider name.
+	Providers map[string]ProviderConfig
+}
+
+// ProviderConfig is one provider's settings for a product, as written in the
+// product's `providers` block of the config file.
+type ProviderConfig map[string]any
+
+// String returns the setting as a string, or "" when unset or not a string.
+func (c ProviderConfig) String(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+// HealthCheck customizes how a product's health is probed. Zero fields keep
+// the defaults: GET /, healthy on any 2xx/3xx status.
+type HealthCheck struct {
+	Path         string // e.g. "/api/health"
+	Method       string // GET or HEAD
+	Headers      map[string]string
+	ExpectStatus []int          // any other status is down
+	BodyContains string         // required substring
+	BodyMatches  *regexp.Regexp // required pattern, compiled when the config is loaded
+	JSON         []JSONAssert   // required JSON field values
+	DegradedOver time.Duration  // a slower median latency is degraded
+	Timeout      time.Duration
+	Retries      int // extra attempts for a probe that finds the site down
+	Probes       int // probes per check; the median latency is reported
+	DownAfter    int // consecutive failed checks before the product is down
+	CertWarnDays int // warn when the TLS certificate expires within this many days
+}
+
+// DefaultCertWarnDays is the certificate expiry warning window when a
+// product's health check doesn't set one.
+const DefaultCertWarnDays = 14
+
+// JSONAssert requires the value at a JSONPath-style path to equal Equals.
+type JSONAssert struct {
+	Path   string // e.g. "$.checks.db"
+	Equals any    // string, number, bool or nil
 }
 
 type Metrics struct {
 	ProductName string
 	Timestamp   time.Time
 	Errors      []string
+	Stale       bool // loaded from the store rather than fetched live; age is time since Timestamp
 
 	// Traffic (PostHog)
 	Visits     int64
 	Uniques    int64
 	BounceRate float64
-	// Trend data (store)
+	// Trend data (store): daily visits, oldest first, ending today (UTC)
 	VisitsHistory []int64
 
 	// Revenue (Stripe)
 	MRR         int64 // cents
 	Subscribers int64
 
+	// Errors (Sentry)
+	UnresolvedIssues int64
+	ErrorEvents      int64 // events received in the last 24h
+	NewIssues        int64 // issues first seen in the last 7 days
+
+	// Deploys (Vercel)
+	DeployState   string    // latest production deployment state: "READY", "ERROR", "BUILDING", ...
+	DeployedAt    time.Time // when the latest production deployment was created
+	BuildDuration int64     // seconds
+	FailedDeploys int64     // failed production deploys in the last 7 days
+
+	// Activity (GitHub)
+	Commits    int64 // default-branch commits in the last 7 days
+	OpenIssues int64 // excluding pull requests
+	OpenPRs    int64
+	Stars      int64
+	CIStatus   string // latest default-branch workflow conclusion: "success", "failure", ...
+
 	// Health
 	HealthStatus string // "healthy", "degraded", "down"
 	ResponseTime int64  // milliseconds
+	HealthReason string // why the product isn't healthy, e.g. "status 503"
+
+	// Health check request phases, milliseconds; they add up to about
+	// ResponseTime. Every probe dials a fresh connection, so all phases are
+	// measured; DNSTime is 0 only when the host is an IP address.
+	DNSTime      int64
+	ConnectTime  int64 // TCP
+	TLSTime      int64 // handshake
+	TTFB         int64 // request written to first response byte
+	DownloadTime int64 // first byte to end of body
+
+	// TLS certificate (health check); CertExpiresAt is zero when none was seen
+	CertExpiresAt time.Time // leaf certificate NotAfter
+	CertIssuer    string    // issuing CA, e.g. "R11"
+	CertError     string    // failed verification, e.g. a hostname mismatch
+	CertExpiring  bool      // expires within the product's warning window
+
+	// Registered providers without dedicated fields, keyed "provider.metric".
+	Values map[string]float64
+	Labels map[string]string
+}
+
+// SetValue records a numeric metric from a registered provider.
+func (m *Metrics) SetValue(key string, value float64) {
+	if m.Values == nil {
+		m.Values = make(map[string]float64)
+	}
+	m.Values[key] = value
+}
+
+// SetLabel records a text metric from a registered provider.
+func (m *Metrics) SetLabel(key, label string) {
+	if m.Labels == nil {
+		m.Labels = make(map[string]string)
+	}
+	m.Labels[key] = label
+}
+
+// Clone returns a deep copy, so the original can keep changing.
+func (m *Metrics) Clone() *Metrics {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.Errors = slices.Clone(m.Errors)
+	clone.VisitsHistory = slices.Clone(m.VisitsHistory)
+	clone.Values = maps.Clone(m.Values)
+	clone.Labels = maps.Clone(m.Labels)
+	return &clone
+}
+
+// DomainRegistration is the registry's record of a registrable domain, as
+// cached between RDAP lookups.
+type DomainRegistration struct {
+	Domain    string // registrable domain, e.g. "example.co.uk"
+	Registrar string
+	ExpiresAt time.Time // zero when the registry doesn't publish one
+	Error     string    // why the lookup failed; cached like a result
+	CheckedAt time.Time
+}
+
+// DomainWarnDays is how close to expiry a domain registration raises
+// SignalDomainExpiring.
+const DomainWarnDays = 30
+
+// Metrics.Values and Labels keys the domain provider reports a registration
+// under.
+const (
+	DomainExpiresKey   = "domain.expires_at" // Values, unix seconds
+	DomainRegistrarKey = "domain.registrar"  // Labels
+)
+
+// Uptime is how long a product spent in each health status over a window,
+// according to its stored snapshots. Time with no snapshot to go by counts
+// toward none of them.
+type Uptime struct {
+	Window   time.Duration
+	Up       time.Duration // healthy
+	Degraded time.Duration // reachable but failing a check; counts as up
+	Down     time.Duration
+}
+
+// Monitored is the part of the window covered by snapshots.
+func (u Uptime) Monitored() time.Duration {
+	return u.Up + u.Degraded + u.Down
+}
+
+// Percent returns the share of monitored time the product was up or
+// degraded, from 0 to 100. ok is false when nothing was monitored.
+func (u Uptime) Percent() (percent float64, ok bool) {
+	monitored := u.Monitored()
+	if monitored <= 0 {
+		return 0, false
+	}
+	return float64(u.Up+u.Degraded) / float64(monitored) * 100, true
+}
+
+// Incident is a stretch of health checks that weren't healthy, from the
+// first degraded or down snapshot to the next healthy one.
+type Incident struct {
+	ID          int64
+	ProductName string
+	StartedAt   time.Time
+	EndedAt     time.Time // zero while the incident is ongoing
+	Status      string    // worst status seen: "degraded" or "down"
+	Reason      string    // health reason when the worst status was first seen
+}
+
+// Ongoing reports whether the product hasn't recovered yet.
+func (i Incident) Ongoing() bool {
+	return i.EndedAt.IsZero()
+}
+
+// Duration is how long the incident lasted, or has lasted so far at now.
+func (i Incident) Duration(now time.Time) time.Duration {
+	if i.Ongoing() {
+		return now.Sub(i.StartedAt)
+	}
+	return i.EndedAt.Sub(i.StartedAt)
+}
+
+// DailyTraffic is one UTC calendar day of traffic for a product.
+type DailyTraffic struct {
+	Day     time.Time // midnight UTC
+	Visits  int64
+	Uniques int64
+}
+
+// Daily metric names recorded in the store's daily time series.
+const (
+	DailyVisits      = "visits"
+	DailyUniques     = "uniques"
+	DailyMRR         = "mrr" // cents, as of the day's last refresh
+	DailySubscribers = "subscribers"
+
+	// MRR movement, in cents. Contraction and churn are positive amounts lost.
+	DailyNewMRR                = "new_mrr"
+	DailyExpansionMRR          = "expansion_mrr"
+	DailyContractionMRR        = "contraction_mrr"
+	DailyChurnedMRR            = "churned_mrr"
+	DailyNewSubscriptions      = "new_subscriptions"
+	DailyCanceledSubscriptions = "canceled_subscriptions"
+)
+
+// SubscriptionMRR is one Stripe subscription's MRR from one Stripe product.
+type SubscriptionMRR struct {
+	Subscription string
+	Product      string // Stripe product ID
+	MRR          int64  // cents
+}
+
+// MRRMovement breaks down how a product's MRR changed over a period, from
+// the end of the day before From through To. Amounts are cents; Contraction
+// and Churned are positive amounts lost.
+type MRRMovement struct {
+	From     time.Time // midnight UTC
+	To       time.Time
+	StartMRR int64
+	EndMRR   int64
+
+	New         int64 // subscriptions that started paying
+	Expansion   int64 // existing subscriptions paying more
+	Contraction int64 // existing subscriptions paying less
+	Churned     int64 // subscriptions that ended
+
+	NewSubscriptions      int64
+	CanceledSubscriptions int64
+}
+
+// Net is the change the movements account for.
+func (m MRRMovement) Net() int64 {
+	return m.New + m.Expansion - m.Contraction - m.Churned
+}
+
+// Other is the change the movements don't account for, such as subscriptions
+// going past due or changes made before their tracking began.
+func (m MRRMovement) Other() int64 {
+	return m.EndMRR - m.StartMRR - m.Net()
+}
+
+// DailyValue is one metric's value for one UTC calendar day.
+type DailyValue struct {
+	Day    time.Time // midnight UTC
+	Metric string
+	Value  int64
+}
+
+// StartOfDayUTC returns midnight UTC of t's UTC calendar day.
+func StartOfDayUTC(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
 
 type Signal string
@@ -36,6 +298,13 @@ const (
 	SignalTraction Signal = "traction" // >100 visits/week
 	SignalDead     Signal = "dead"     // <10 visits/week, no revenue
 	SignalNeutral  Signal = "neutral"
+
+	// Warnings are raised independently of the traction signal.
+	SignalDeployFailed Signal = "deploy_failed" // latest production deploy failed
+	SignalCertInvalid  Signal = "cert_invalid"  // TLS certificate failed verification
+	SignalCertExpiring Signal = "cert_expiring" // TLS certificate expires soon
+
+	SignalDomainExpiring Signal = "domain_expiring" // registration expires within DomainWarnDays
 )
 
 func (m *Metrics) ComputeSignal() Signal {
@@ -47,3 +316,55 @@ func (m *Metrics) ComputeSignal() Signal {
 	}
 	return SignalNeutral
 }
+
+// Warnings returns the problems that need attention, in display order.
+func (m *Metrics) Warnings() []Signal {
+	var warnings []Signal
+	if m.DeployState == "ERROR" {
+		warnings = append(warnings, SignalDeployFailed)
+	}
+	switch {
+	case m.CertError != "":
+		warnings = append(warnings, SignalCertInvalid)
+	case m.CertExpiring:
+		warnings = append(warnings, SignalCertExpiring)
+	}
+	if expiresAt := m.DomainExpiresAt(); !expiresAt.IsZero() && expiresAt.Before(m.Timestamp.AddDate(0, 0, DomainWarnDays)) {
+		warnings = append(warnings, SignalDomainExpiring)
+	}
+	return warnings
+}
+
+// CertDaysLeft returns whole days from Timestamp until the certificate
+// expires, negative once it has. It is 0 when no certificate was seen.
+func (m *Metrics) CertDaysLeft() int {
+	return daysUntil(m.Timestamp, m.CertExpiresAt)
+}
+
+// DomainExpiresAt returns when the domain registration expires, from the
+// domain provider's DomainExpiresKey value. It is zero when unknown.
+func (m *Metrics) DomainExpiresAt() time.Time {
+	expiresAt, ok := m.Values[DomainExpiresKey]
+	if !ok || expiresAt <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(expiresAt), 0)
+}
+
+// DomainRegistrar returns the domain's registrar, or "" when unknown.
+func (m *Metrics) DomainRegistrar() string {
+	return m.Labels[DomainRegistrarKey]
+}
+
+// DomainDaysLeft returns whole days from Timestamp until the domain
+// registration expires, negative once it has. It is 0 when unknown.
+func (m *Metrics) DomainDaysLeft() int {
+	return daysUntil(m.Timestamp, m.DomainExpiresAt())
+}
+
+func daysUntil(from, to time.Time) int {
+	if to.IsZero() {
+		return 0
+	}
+	return int(math.Floor(to.Sub(from).Hours() / 24))
+}
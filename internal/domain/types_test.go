@@ -1,6 +1,10 @@
 package domain
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestComputeSignal(t *testing.T) {
 	tests := []struct {
@@ -49,3 +53,102 @@ func TestComputeSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Metrics
+		want []Signal
+	}{
+		{
+			name: "none by default",
+			m:    Metrics{},
+			want: nil,
+		},
+		{
+			name: "deploy failed",
+			m:    Metrics{DeployState: "ERROR"},
+			want: []Signal{SignalDeployFailed},
+		},
+		{
+			name: "ready deploy is fine",
+			m:    Metrics{DeployState: "READY", FailedDeploys: 2},
+			want: nil,
+		},
+		{
+			name: "certificate expiring",
+			m:    Metrics{CertExpiring: true},
+			want: []Signal{SignalCertExpiring},
+		},
+		{
+			name: "invalid certificate outranks expiring",
+			m:    Metrics{DeployState: "ERROR", CertError: "expired", CertExpiring: true},
+			want: []Signal{SignalDeployFailed, SignalCertInvalid},
+		},
+		{
+			name: "domain expiring within the window",
+			m:    Metrics{Timestamp: time.Unix(0, 0), Values: map[string]float64{DomainExpiresKey: float64(time.Unix(0, 0).AddDate(0, 0, DomainWarnDays-1).Unix())}},
+			want: []Signal{SignalDomainExpiring},
+		},
+		{
+			name: "domain renewed past the window",
+			m:    Metrics{Timestamp: time.Unix(0, 0), Values: map[string]float64{DomainExpiresKey: float64(time.Unix(0, 0).AddDate(0, 0, DomainWarnDays+1).Unix())}},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.m.Warnings()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Warnings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertDaysLeft(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		expires time.Time
+		want    int
+	}{
+		{name: "no certificate", want: 0},
+		{name: "whole days", expires: now.Add(10 * 24 * time.Hour), want: 10},
+		{name: "partial day rounds down", expires: now.Add(36 * time.Hour), want: 1},
+		{name: "expired", expires: now.Add(-time.Hour), want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Metrics{Timestamp: now, CertExpiresAt: tt.expires}
+			if got := m.CertDaysLeft(); got != tt.want {
+				t.Errorf("CertDaysLeft() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUptimePercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		uptime Uptime
+		want   float64
+		wantOK bool
+	}{
+		{name: "nothing monitored"},
+		{name: "always up", uptime: Uptime{Up: time.Hour}, want: 100, wantOK: true},
+		{name: "degraded counts as up", uptime: Uptime{Up: 30 * time.Minute, Degraded: 30 * time.Minute}, want: 100, wantOK: true},
+		{name: "unmonitored time is ignored", uptime: Uptime{Window: 24 * time.Hour, Up: 3 * time.Hour, Down: time.Hour}, want: 75, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.uptime.Percent()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Percent() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// ExecPrefix is prepended to a plugin's name to find its executable on PATH,
+// git-style: a product's `providers.queue` block runs overmind-provider-queue.
+const ExecPrefix = "overmind-provider-"
+
+// DefaultExecTimeout bounds one plugin run when no timeout is configured.
+const DefaultExecTimeout = 10 * time.Second
+
+// execMaxOutput caps what a plugin may write to stdout or stderr.
+const execMaxOutput = 1 << 20
+
+// ExecSpec configures an external-executable provider.
+type ExecSpec struct {
+	Command []string      // argv; empty runs ExecPrefix+name from PATH
+	Timeout time.Duration // per product run; 0 uses DefaultExecTimeout
+	Columns []Column      // keys without the "<name>." prefix
+}
+
+// ExecProvider runs an external executable once per product. The plugin
+// reads an ExecRequest as JSON on stdin and writes an ExecResponse as JSON on
+// stdout. Anything it writes to stderr is reported in Metrics.Errors.
+type ExecProvider struct {
+	name    string
+	command []string
+	timeout time.Duration
+	columns []Column
+}
+
+// ExecRequest is written to a plugin's stdin.
+type ExecRequest struct {
+	Product ExecProduct           `json:"product"`
+	Config  domain.ProviderConfig `json:"config"`
+}
+
+type ExecProduct struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+}
+
+// ExecResponse is read from a plugin's stdout. Keys are stored as
+// "<name>.<key>" in Metrics.Values and Metrics.Labels.
+type ExecResponse struct {
+	Values map[string]float64 `json:"values"`
+	Labels map[string]string  `json:"labels"`
+	Errors []string           `json:"errors"`
+}
+
+// NewExecProvider resolves the plugin's executable and returns its provider.
+func NewExecProvider(name string, spec ExecSpec) (*ExecProvider, error) {
+	if name == "" {
+		return nil, fmt.Errorf("providers: plugin name is empty")
+	}
+	command := append([]string(nil), spec.Command...)
+	if len(command) == 0 {
+		command = []string{ExecPrefix + name}
+	}
+	path, err := exec.LookPath(command[0])
+	if err != nil {
+		return nil, fmt.Errorf("providers: plugin %q: %w", name, err)
+	}
+	command[0] = path
+
+	for _, c := range spec.Columns {
+		if !validFormat(c.Format) {
+			return nil, fmt.Errorf("providers: plugin %q column %q: unknown format %q", name, c.Key, c.Format)
+		}
+	}
+
+	timeout := spec.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+	return &ExecProvider{
+		name:    name,
+		command: command,
+		timeout: timeout,
+		columns: append([]Column(nil), spec.Columns...),
+	}, nil
+}
+
+func (p *ExecProvider) Name() string  { return p.name }
+func (p *ExecProvider) Label() string { return p.name }
+
+func (p *ExecProvider) Enabled(product domain.Product) bool {
+	_, ok := product.Providers[p.name]
+	return ok
+}
+
+func (p *ExecProvider) Columns([]domain.Product) []Column {
+	columns := make([]Column, 0, len(p.columns))
+	for _, c := range p.columns {
+		c.Key = p.name + "." + c.Key
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+func (p *ExecProvider) Begin(context.Context, []domain.Product, Window) CollectFunc {
+	return p.collect
+}
+
+func (p *ExecProvider) collect(ctx context.Context, product domain.Product, s *Sample) error {
+	input, err := json.Marshal(ExecRequest{
+		Product: ExecProduct{Name: product.Name, Domain: product.Domain},
+		Config:  product.Providers[p.name],
+	})
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	// #nosec G204 -- the command comes from the user's own config or PATH.
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &limitedBuffer{max: execMaxOutput}
+	stderr := &limitedBuffer{max: execMaxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't wait forever on pipes held open by the plugin's own children.
+	cmd.WaitDelay = time.Second
+
+	runErr := cmd.Run()
+	for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			s.Metrics.Errors = append(s.Metrics.Errors, p.Label()+": "+line)
+		}
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", p.timeout)
+	}
+	if runErr != nil {
+		return fmt.Errorf("run %s: %w", p.command[0], runErr)
+	}
+	if stdout.truncated {
+		return fmt.Errorf("output exceeds %d bytes", execMaxOutput)
+	}
+
+	var resp ExecResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return fmt.Errorf("decode output: %w", err)
+	}
+	for key, value := range resp.Values {
+		s.Metrics.SetValue(p.name+"."+key, value)
+	}
+	for key, label := range resp.Labels {
+		s.Metrics.SetLabel(p.name+"."+key, label)
+	}
+	for _, msg := range resp.Errors {
+		s.Metrics.Errors = append(s.Metrics.Errors, p.Label()+": "+msg)
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first max bytes written and drops the rest, so a
+// runaway plugin can't exhaust memory.
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
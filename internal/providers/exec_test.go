@@ -0,0 +1,206 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// writePlugin writes an executable shell script named overmind-provider-<name>
+// into dir and returns its path.
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, ExecPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	return path
+}
+
+func runPlugin(t *testing.T, p *ExecProvider, product domain.Product) (*Sample, error) {
+	t.Helper()
+	s := &Sample{Metrics: &domain.Metrics{ProductName: product.Name}}
+	collect := p.Begin(context.Background(), []domain.Product{product}, Window{})
+	return s, collect(context.Background(), product, s)
+}
+
+func TestExecProvider(t *testing.T) {
+	product := domain.Product{
+		Name:   "App",
+		Domain: "app.com",
+		Providers: map[string]domain.ProviderConfig{
+			"queue": {"region": "eu"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "sends product config on stdin and stores values and labels",
+			fn: func(t *testing.T) {
+				dir := t.TempDir()
+				input := filepath.Join(dir, "stdin.json")
+				path := writePlugin(t, dir, "queue", `cat > "`+input+`"
+echo '{"values": {"depth": 42.5}, "labels": {"state": "ok"}, "errors": ["dlq not configured"]}'
+`)
+				p, err := NewExecProvider("queue", ExecSpec{Command: []string{path}})
+				if err != nil {
+					t.Fatalf("NewExecProvider() error = %v", err)
+				}
+
+				s, err := runPlugin(t, p, product)
+				if err != nil {
+					t.Fatalf("collect() error = %v", err)
+				}
+				if got := s.Metrics.Values["queue.depth"]; got != 42.5 {
+					t.Errorf("Values[queue.depth] = %v, want 42.5", got)
+				}
+				if got := s.Metrics.Labels["queue.state"]; got != "ok" {
+					t.Errorf("Labels[queue.state] = %q, want ok", got)
+				}
+				if len(s.Metrics.Errors) != 1 || s.Metrics.Errors[0] != "queue: dlq not configured" {
+					t.Errorf("Errors = %v, want [queue: dlq not configured]", s.Metrics.Errors)
+				}
+
+				raw, err := os.ReadFile(input)
+				if err != nil {
+					t.Fatalf("read stdin: %v", err)
+				}
+				var req ExecRequest
+				if err := json.Unmarshal(raw, &req); err != nil {
+					t.Fatalf("decode stdin %q: %v", raw, err)
+				}
+				if req.Product.Name != "App" || req.Product.Domain != "app.com" || req.Config.String("region") != "eu" {
+					t.Errorf("request = %+v, want App/app.com with region eu", req)
+				}
+			},
+		},
+		{
+			name: "captures stderr into errors",
+			fn: func(t *testing.T) {
+				path := writePlugin(t, t.TempDir(), "queue", `echo 'warming cache' >&2
+echo 'rate limited' >&2
+echo '{}'
+`)
+				p, err := NewExecProvider("queue", ExecSpec{Command: []string{path}})
+				if err != nil {
+					t.Fatalf("NewExecProvider() error = %v", err)
+				}
+
+				s, err := runPlugin(t, p, product)
+				if err != nil {
+					t.Fatalf("collect() error = %v", err)
+				}
+				want := []string{"queue: warming cache", "queue: rate limited"}
+				if strings.Join(s.Metrics.Errors, "|") != strings.Join(want, "|") {
+					t.Errorf("Errors = %v, want %v", s.Metrics.Errors, want)
+				}
+			},
+		},
+		{
+			name: "reports a non-zero exit",
+			fn: func(t *testing.T) {
+				path := writePlugin(t, t.TempDir(), "queue", "echo 'bad token' >&2\nexit 3\n")
+				p, err := NewExecProvider("queue", ExecSpec{Command: []string{path}})
+				if err != nil {
+					t.Fatalf("NewExecProvider() error = %v", err)
+				}
+
+				s, err := runPlugin(t, p, product)
+				if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+					t.Fatalf("collect() error = %v, want exit status 3", err)
+				}
+				if len(s.Metrics.Errors) != 1 || s.Metrics.Errors[0] != "queue: bad token" {
+					t.Errorf("Errors = %v, want [queue: bad token]", s.Metrics.Errors)
+				}
+			},
+		},
+		{
+			name: "rejects malformed output",
+			fn: func(t *testing.T) {
+				path := writePlugin(t, t.TempDir(), "queue", "echo 'depth=42'\n")
+				p, err := NewExecProvider("queue", ExecSpec{Command: []string{path}})
+				if err != nil {
+					t.Fatalf("NewExecProvider() error = %v", err)
+				}
+
+				if _, err := runPlugin(t, p, product); err == nil || !strings.Contains(err.Error(), "decode output") {
+					t.Fatalf("collect() error = %v, want decode error", err)
+				}
+			},
+		},
+		{
+			name: "kills a plugin that overruns its timeout",
+			fn: func(t *testing.T) {
+				path := writePlugin(t, t.TempDir(), "queue", "exec sleep 10\n")
+				p, err := NewExecProvider("queue", ExecSpec{Command: []string{path}, Timeout: 100 * time.Millisecond})
+				if err != nil {
+					t.Fatalf("NewExecProvider() error = %v", err)
+				}
+
+				start := time.Now()
+				_, err = runPlugin(t, p, product)
+				if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+					t.Fatalf("collect() error = %v, want timeout", err)
+				}
+				if elapsed := time.Since(start); elapsed > 5*time.Second {
+					t.Errorf("collect() took %s, want the plugin killed promptly", elapsed)
+				}
+			},
+		},
+		{
+			name: "finds overmind-provider-<name> on PATH",
+			fn: func(t *testing.T) {
+				dir := t.TempDir()
+				writePlugin(t, dir, "queue", `echo '{"values": {"depth": 7}}'`+"\n")
+				t.Setenv("PATH", dir)
+
+				p, err := NewExecProvider("queue", ExecSpec{
+					Columns: []Column{{Key: "depth", Title: "QUEUE"}},
+				})
+				if err != nil {
+					t.Fatalf("NewExecProvider() error = %v", err)
+				}
+				if cols := p.Columns(nil); len(cols) != 1 || cols[0].Key != "queue.depth" {
+					t.Errorf("Columns() = %+v, want key queue.depth", cols)
+				}
+				s, err := runPlugin(t, p, product)
+				if err != nil {
+					t.Fatalf("collect() error = %v", err)
+				}
+				if got := s.Metrics.Values["queue.depth"]; got != 7 {
+					t.Errorf("Values[queue.depth] = %v, want 7", got)
+				}
+
+				if _, err := NewExecProvider("missing", ExecSpec{}); err == nil {
+					t.Errorf("NewExecProvider(missing) error = nil, want not found")
+				}
+			},
+		},
+		{
+			name: "rejects unknown column formats",
+			fn: func(t *testing.T) {
+				path := writePlugin(t, t.TempDir(), "queue", "echo '{}'\n")
+				_, err := NewExecProvider("queue", ExecSpec{
+					Command: []string{path},
+					Columns: []Column{{Key: "depth", Title: "QUEUE", Format: "bytes"}},
+				})
+				if err == nil {
+					t.Fatalf("NewExecProvider() error = nil, want unknown format")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
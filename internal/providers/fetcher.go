@@ -2,6 +2,9 @@ package providers
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"slices"
 	"sync"
 	"time"
 
@@ -14,111 +17,463 @@ import (
 const trendDays = 7
 
 type MetricsFetcher struct {
-	stripe  *StripeClient
-	posthog *PostHogClient
-	store   *store.Store
+	registry     *Registry
+	store        *store.Store
+	statusMaxAge time.Duration
 }
 
-func NewMetricsFetcher(stripe *StripeClient, posthog *PostHogClient, store *store.Store) *MetricsFetcher {
+// NewMetricsFetcher fetches from every provider in registry, in registration
+// order, and caches results in store when it is non-nil.
+func NewMetricsFetcher(registry *Registry, store *store.Store) *MetricsFetcher {
+	if registry == nil {
+		registry = NewRegistry()
+	}
 	return &MetricsFetcher{
-		stripe:  stripe,
-		posthog: posthog,
-		store:   store,
+		registry:     registry,
+		store:        store,
+		statusMaxAge: defaultStatusMaxAge,
+	}
+}
+
+// SetSnapshotInterval tells f how often snapshots are saved, so a stored
+// health status holds for two intervals before the silence counts as a gap
+// in monitoring rather than more of the same status.
+func (f *MetricsFetcher) SetSnapshotInterval(every time.Duration) {
+	if every > 0 {
+		f.statusMaxAge = 2 * every
+	}
+}
+
+// StatusMaxAge returns the longest a stored health status is assumed to hold
+// without a newer snapshot.
+func (f *MetricsFetcher) StatusMaxAge() time.Duration {
+	return f.statusMaxAge
+}
+
+// Columns returns the dashboard columns of every registered provider that
+// at least one of products uses.
+func (f *MetricsFetcher) Columns(products []domain.Product) []Column {
+	var columns []Column
+	for _, provider := range f.registry.Providers() {
+		cp, ok := provider.(ColumnProvider)
+		if !ok || !usedBy(provider, products) {
+			continue
+		}
+		for _, c := range cp.Columns(products) {
+			c.Provider = provider.Name()
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+func usedBy(provider Provider, products []domain.Product) bool {
+	for _, p := range products {
+		if provider.Enabled(p) {
+			return true
+		}
 	}
+	return false
+}
+
+// Built-in provider names, as registered and reported in Update.Provider.
+const (
+	ProviderPostHog = "posthog"
+	ProviderStripe  = "stripe"
+	ProviderSentry  = "sentry"
+	ProviderVercel  = "vercel"
+	ProviderGitHub  = "github"
+	ProviderHTTP    = "http"
+	ProviderHealth  = "health"
+	ProviderDomain  = "domain"
+)
+
+// Update is a partial or final result for one product, emitted by FetchEach.
+type Update struct {
+	ProductName string
+	Metrics     *domain.Metrics // a copy; safe to retain
+	Provider    string          // provider that just finished; empty on the final update
+	Done        bool            // every provider finished and the snapshot was stored
 }
 
+// FetchAll fetches every product and returns the final metrics keyed by
+// product name once all of them have finished.
 func (f *MetricsFetcher) FetchAll(ctx context.Context, products []domain.Product) map[string]*domain.Metrics {
+	collected := make(map[string]*domain.Metrics, len(products))
+	f.FetchEach(ctx, products, func(u Update) {
+		if u.Done {
+			collected[u.ProductName] = u.Metrics
+		}
+	})
+	return collected
+}
+
+// FetchEach fetches every product concurrently and calls onUpdate as soon as
+// each provider finishes for a product, then once more with Done set. Calls
+// are serialized, so onUpdate needs no locking, but it should return quickly:
+// a slow callback holds up the fetch. FetchEach returns after the last update.
+func (f *MetricsFetcher) FetchEach(ctx context.Context, products []domain.Product, onUpdate func(Update)) {
 	products = append([]domain.Product(nil), products...)
 
 	now := time.Now()
-	weekAgo := now.AddDate(0, 0, -7)
-	trendStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(trendDays - 1))
-
 	var mu sync.Mutex
-	collected := make(map[string]*domain.Metrics, len(products))
+	emit := func(u Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		onUpdate(u)
+	}
 
 	group, ctx := errgroup.WithContext(ctx)
+	w := Window{
+		Now:        now,
+		WeekAgo:    now.AddDate(0, 0, -7),
+		TrendStart: domain.StartOfDayUTC(now).AddDate(0, 0, -(trendDays - 1)),
+		TrendDays:  trendDays,
+	}
+	var collectors []collector
+	for _, provider := range f.registry.Providers() {
+		if !usedBy(provider, products) {
+			continue
+		}
+		collectors = append(collectors, collector{
+			provider: provider,
+			collect:  provider.Begin(ctx, products, w),
+		})
+	}
+
 	for _, p := range products {
 		p := p
 		group.Go(func() error {
-			metric := f.fetchProductMetrics(ctx, p, weekAgo, now, trendStart)
-			mu.Lock()
-			collected[p.Name] = metric
-			mu.Unlock()
+			metric := f.fetchProductMetrics(ctx, p, w, collectors, func(provider string, partial *domain.Metrics) {
+				emit(Update{ProductName: p.Name, Metrics: partial, Provider: provider})
+			})
+			emit(Update{ProductName: p.Name, Metrics: metric, Done: true})
 			return nil
 		})
 	}
 
 	// Best-effort wait; individual fetch errors are captured per metric.
 	_ = group.Wait()
-	return collected
 }
 
-func (f *MetricsFetcher) fetchProductMetrics(ctx context.Context, p domain.Product, weekAgo, now, trendStart time.Time) *domain.Metrics {
-	metric := &domain.Metrics{
-		ProductName: p.Name,
-		Timestamp:   now,
+// LoadCached returns the last stored metrics for each product, marked stale,
+// without touching the network. Products with no stored snapshot are omitted.
+func (f *MetricsFetcher) LoadCached(ctx context.Context, products []domain.Product) (map[string]*domain.Metrics, error) {
+	cached := make(map[string]*domain.Metrics, len(products))
+	if f.store == nil {
+		return cached, nil
 	}
 
-	if p.PostHogHost != "" && f.posthog != nil {
-		if analytics, err := f.posthog.GetPageviews(ctx, p.PostHogHost, weekAgo, now); err == nil {
-			metric.Visits = analytics.Pageviews
-			metric.Uniques = analytics.Visitors
-		} else {
-			metric.Errors = append(metric.Errors, "PostHog: "+err.Error())
+	now := time.Now()
+	trendStart := domain.StartOfDayUTC(now).AddDate(0, 0, -(trendDays - 1))
+	for _, p := range products {
+		metric, err := f.store.GetLatestMetrics(ctx, p.Name)
+		if err != nil {
+			return nil, err
+		}
+		if metric == nil {
+			continue
+		}
+		metric.Stale = true
+		if visits, err := f.store.GetDailySeries(ctx, p.Name, domain.DailyVisits, trendStart, now); err == nil {
+			metric.VisitsHistory = buildDailyHistory(visits, now, trendDays)
 		}
+		cached[p.Name] = metric
 	}
+	return cached, nil
+}
 
-	if p.StripeID != "" && f.stripe != nil {
-		if mrr, subs, err := f.stripe.GetMRRForProduct(ctx, p.StripeID); err == nil {
-			metric.MRR = mrr
-			metric.Subscribers = subs
-		} else {
-			metric.Errors = append(metric.Errors, "Stripe: "+err.Error())
+// History returns productName's stored snapshots since from, oldest first.
+func (f *MetricsFetcher) History(ctx context.Context, productName string, from time.Time) ([]*domain.Metrics, error) {
+	if f.store == nil {
+		return nil, nil
+	}
+	return f.store.GetMetricsRange(ctx, productName, from, time.Now())
+}
+
+// UptimeWindows are the periods uptime is reported over, shortest first.
+var UptimeWindows = []time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// defaultStatusMaxAge is StatusMaxAge until SetSnapshotInterval is called.
+const defaultStatusMaxAge = time.Hour
+
+// Uptime returns each product's uptime over every UptimeWindows period ending
+// at now, in that order, from the stored health snapshots.
+func (f *MetricsFetcher) Uptime(ctx context.Context, products []domain.Product, now time.Time) (map[string][]domain.Uptime, error) {
+	uptime := make(map[string][]domain.Uptime, len(products))
+	if f.store == nil {
+		return uptime, nil
+	}
+	for _, p := range products {
+		windows := make([]domain.Uptime, 0, len(UptimeWindows))
+		for _, window := range UptimeWindows {
+			u, err := f.store.GetUptime(ctx, p.Name, now.Add(-window), now, f.statusMaxAge)
+			if err != nil {
+				return nil, err
+			}
+			windows = append(windows, u)
+		}
+		uptime[p.Name] = windows
+	}
+	return uptime, nil
+}
+
+// DailyUptime returns each product's uptime for the last days UTC days,
+// oldest first, with today's running until now.
+func (f *MetricsFetcher) DailyUptime(ctx context.Context, products []domain.Product, now time.Time, days int) (map[string][]domain.Uptime, error) {
+	uptime := make(map[string][]domain.Uptime, len(products))
+	if f.store == nil {
+		return uptime, nil
+	}
+	from := domain.StartOfDayUTC(now).AddDate(0, 0, -(days - 1))
+	for _, p := range products {
+		daily, err := f.store.GetDailyUptime(ctx, p.Name, from, now, f.statusMaxAge)
+		if err != nil {
+			return nil, err
 		}
+		uptime[p.Name] = daily
+	}
+	return uptime, nil
+}
+
+// MRRMovement returns the MRR movement of each product with a Stripe ID from
+// the start of from's UTC day through now, summed from the daily series.
+// StartMRR is the MRR stored for the day before; when there is none, as on a
+// fresh install, it is worked back from EndMRR and the movements.
+func (f *MetricsFetcher) MRRMovement(ctx context.Context, products []domain.Product, from, now time.Time) (map[string]domain.MRRMovement, error) {
+	movements := make(map[string]domain.MRRMovement)
+	if f.store == nil {
+		return movements, nil
 	}
+	from = domain.StartOfDayUTC(from)
+	for _, p := range products {
+		if p.StripeID == "" {
+			continue
+		}
+		series, err := f.store.GetDailyMetrics(ctx, p.Name, from.AddDate(0, 0, -1), now)
+		if err != nil {
+			return nil, err
+		}
 
-	if p.Domain != "" {
-		if health, err := CheckHealth(ctx, p.Domain); err == nil {
-			metric.HealthStatus = health.Status
-			metric.ResponseTime = health.ResponseTime
+		m := domain.MRRMovement{From: from, To: now}
+		sum := func(metric string) int64 {
+			var total int64
+			for _, v := range series[metric] {
+				if !v.Day.Before(from) {
+					total += v.Value
+				}
+			}
+			return total
+		}
+		m.New = sum(domain.DailyNewMRR)
+		m.Expansion = sum(domain.DailyExpansionMRR)
+		m.Contraction = sum(domain.DailyContractionMRR)
+		m.Churned = sum(domain.DailyChurnedMRR)
+		m.NewSubscriptions = sum(domain.DailyNewSubscriptions)
+		m.CanceledSubscriptions = sum(domain.DailyCanceledSubscriptions)
+
+		mrr := series[domain.DailyMRR]
+		if len(mrr) > 0 {
+			m.EndMRR = mrr[len(mrr)-1].Value
+		}
+		if len(mrr) > 0 && mrr[0].Day.Before(from) {
+			m.StartMRR = mrr[0].Value
 		} else {
-			metric.Errors = append(metric.Errors, "Health: "+err.Error())
+			m.StartMRR = m.EndMRR - m.Net()
+		}
+		movements[p.Name] = m
+	}
+	return movements, nil
+}
+
+// Incidents returns the products' incidents that were ongoing at some point
+// since from, newest first. Incidents of products no longer configured are
+// left out.
+func (f *MetricsFetcher) Incidents(ctx context.Context, products []domain.Product, from time.Time) ([]domain.Incident, error) {
+	if f.store == nil {
+		return nil, nil
+	}
+	incidents, err := f.store.GetIncidents(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(incidents, func(i domain.Incident) bool {
+		return !slices.ContainsFunc(products, func(p domain.Product) bool { return p.Name == i.ProductName })
+	}), nil
+}
+
+// collector is one provider's per-product collection step for a refresh.
+type collector struct {
+	provider Provider
+	collect  CollectFunc
+}
+
+// fetchProductMetrics runs every enabled provider for p at once, calling
+// progress with a copy of the metrics gathered so far as each one finishes,
+// so a slow provider doesn't hold back the others' results.
+func (f *MetricsFetcher) fetchProductMetrics(ctx context.Context, p domain.Product, w Window, collectors []collector, progress func(provider string, partial *domain.Metrics)) *domain.Metrics {
+	now, trendStart := w.Now, w.TrendStart
+	sample := &Sample{Metrics: &domain.Metrics{
+		ProductName: p.Name,
+		Timestamp:   now,
+	}}
+	metric := sample.Metrics
+
+	var (
+		mu    sync.Mutex
+		group errgroup.Group
+		errs  = make([][]string, len(collectors)) // per collector, so the merged order is stable
+	)
+	for i, c := range collectors {
+		if !c.provider.Enabled(p) {
+			continue
 		}
+		group.Go(func() error {
+			// Each provider fills its own sample, merged in under mu.
+			own := &Sample{Metrics: &domain.Metrics{ProductName: p.Name, Timestamp: now}}
+			if err := c.collect(ctx, p, own); err != nil {
+				own.Metrics.Errors = append(own.Metrics.Errors, c.provider.Label()+": "+err.Error())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			errs[i] = own.Metrics.Errors
+			mergeMetrics(metric, own.Metrics)
+			sample.Daily = append(sample.Daily, own.Daily...)
+			progress(c.provider.Name(), metric.Clone())
+			return nil
+		})
 	}
+	// Collection errors are recorded in the metrics, never returned.
+	_ = group.Wait()
+	metric.Errors = slices.Concat(errs...)
 
 	if f.store != nil {
 		// Best-effort cache write; live metrics should still surface even if storage fails.
-		_ = f.store.SaveMetrics(ctx, metric)
-		if history, err := f.store.GetMetricsRange(ctx, p.Name, trendStart, now); err == nil {
-			metric.VisitsHistory = buildVisitsHistory(history, now, trendDays)
+		if err := f.store.SaveMetrics(ctx, metric); err != nil {
+			metric.Errors = append(metric.Errors, "Store: "+err.Error())
+		}
+		if err := f.store.TrackIncident(ctx, p.Name, metric.HealthStatus, metric.HealthReason, now); err != nil {
+			metric.Errors = append(metric.Errors, "Store: "+err.Error())
+		}
+		if err := f.store.SaveDailyMetrics(ctx, p.Name, sample.Daily); err != nil {
+			metric.Errors = append(metric.Errors, "Store: "+err.Error())
+		}
+		if visits, err := f.store.GetDailySeries(ctx, p.Name, domain.DailyVisits, trendStart, now); err == nil {
+			metric.VisitsHistory = buildDailyHistory(visits, now, trendDays)
 		}
 	}
 
 	return metric
 }
 
-func buildVisitsHistory(metrics []*domain.Metrics, now time.Time, days int) []int64 {
+// mergeMetrics copies what one provider collected into dst: every field src
+// set, entries of its Values and Labels, and its errors. Providers fill
+// disjoint fields, so nothing is overwritten.
+func mergeMetrics(dst, src *domain.Metrics) {
+	d, v := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for i := range v.NumField() {
+		field := v.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.Slice:
+			d.Field(i).Set(reflect.AppendSlice(d.Field(i), field))
+		case reflect.Map:
+			if d.Field(i).IsNil() {
+				d.Field(i).Set(reflect.MakeMap(field.Type()))
+			}
+			for iter := field.MapRange(); iter.Next(); {
+				d.Field(i).SetMapIndex(iter.Key(), iter.Value())
+			}
+		default:
+			d.Field(i).Set(field)
+		}
+	}
+}
+
+// buildDailyHistory returns one value per UTC day ending today, oldest first.
+// Days without a stored value are zero.
+func buildDailyHistory(values []domain.DailyValue, now time.Time, days int) []int64 {
 	if days <= 0 {
 		return nil
 	}
 
-	loc := now.Location()
-	dayVisits := make(map[string]int64, days)
-	for _, metric := range metrics {
-		if metric == nil {
-			continue
-		}
-		ts := metric.Timestamp.In(loc)
-		day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, loc)
-		dayVisits[day.Format("2006-01-02")] = metric.Visits
+	byDay := make(map[time.Time]int64, len(values))
+	for _, v := range values {
+		byDay[domain.StartOfDayUTC(v.Day)] = v.Value
 	}
 
 	history := make([]int64, 0, days)
-	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -(days - 1))
+	start := domain.StartOfDayUTC(now).AddDate(0, 0, -(days - 1))
 	for i := 0; i < days; i++ {
-		day := start.AddDate(0, 0, i)
-		history = append(history, dayVisits[day.Format("2006-01-02")])
+		history = append(history, byDay[start.AddDate(0, 0, i)])
 	}
 	return history
 }
+
+// dailyTrafficValues converts PostHog daily buckets into visits and uniques
+// values for each of `days` UTC days starting at from. Days without traffic
+// are written as explicit zeros.
+func dailyTrafficValues(traffic []domain.DailyTraffic, from time.Time, days int) []domain.DailyValue {
+	byDay := make(map[time.Time]domain.DailyTraffic, len(traffic))
+	for _, d := range traffic {
+		byDay[domain.StartOfDayUTC(d.Day)] = d
+	}
+
+	values := make([]domain.DailyValue, 0, 2*days)
+	for i := 0; i < days; i++ {
+		day := from.AddDate(0, 0, i)
+		d := byDay[day]
+		values = append(values,
+			domain.DailyValue{Day: day, Metric: domain.DailyVisits, Value: d.Visits},
+			domain.DailyValue{Day: day, Metric: domain.DailyUniques, Value: d.Uniques},
+		)
+	}
+	return values
+}
+
+// Backfill loads true per-day traffic from PostHog for the last `days` UTC days
+// (including today) and upserts it into the store. It returns the number of
+// days written per product.
+func (f *MetricsFetcher) Backfill(ctx context.Context, products []domain.Product, days int) (map[string]int, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("backfill: days must be positive, got %d", days)
+	}
+	registered, _ := f.registry.Lookup(ProviderPostHog)
+	posthog, ok := registered.(*PostHogClient)
+	if !ok {
+		return nil, fmt.Errorf("backfill: posthog is not configured")
+	}
+	if f.store == nil {
+		return nil, fmt.Errorf("backfill: store is not configured")
+	}
+
+	hostFilters := make([]string, 0, len(products))
+	for _, p := range products {
+		if p.PostHogHost != "" {
+			hostFilters = append(hostFilters, p.PostHogHost)
+		}
+	}
+
+	now := time.Now()
+	from := domain.StartOfDayUTC(now).AddDate(0, 0, -(days - 1))
+	daily, err := posthog.GetDailyPageviewsByHost(ctx, hostFilters, from, now)
+	if err != nil {
+		return nil, fmt.Errorf("backfill: %w", err)
+	}
+
+	written := make(map[string]int, len(products))
+	for _, p := range products {
+		if p.PostHogHost == "" {
+			continue
+		}
+		values := dailyTrafficValues(daily[p.PostHogHost], from, days)
+		if err := f.store.SaveDailyMetrics(ctx, p.Name, values); err != nil {
+			return written, fmt.Errorf("backfill: %s: %w", p.Name, err)
+		}
+		written[p.Name] = days
+	}
+	return written, nil
+}
@@ -0,0 +1,489 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/store"
+)
+
+func newTestRegistry(t *testing.T, providers ...Provider) *Registry {
+	t.Helper()
+
+	registry := NewRegistry()
+	for _, p := range providers {
+		if err := registry.Register(p); err != nil {
+			t.Fatalf("Register(%s) error = %v", p.Name(), err)
+		}
+	}
+	return registry
+}
+
+func TestFetchAllSharesStripeScan(t *testing.T) {
+	page := `{"has_more": false, "data": [
+		{"id": "sub_1", "status": "active", "items": {"data": [{"price": {"product": "prod_0", "unit_amount": 900}}]}},
+		{"id": "sub_2", "status": "active", "items": {"data": [{"price": {"product": "prod_1", "unit_amount": 400}}]}}
+	]}`
+
+	for _, productCount := range []int{1, 3, 15} {
+		t.Run(fmt.Sprintf("%d products", productCount), func(t *testing.T) {
+			var calls int32
+			server := newStripeTestServer(t, []string{page}, "", &calls)
+			stripe := NewStripeClient("sk_test")
+			stripe.baseURL = server.URL
+
+			products := make([]domain.Product, 0, productCount)
+			for i := 0; i < productCount; i++ {
+				products = append(products, domain.Product{Name: fmt.Sprintf("App%d", i), StripeID: fmt.Sprintf("prod_%d", i)})
+			}
+
+			fetcher := NewMetricsFetcher(newTestRegistry(t, stripe), nil)
+			metrics := fetcher.FetchAll(context.Background(), products)
+
+			if calls != 1 {
+				t.Fatalf("subscription list calls = %d, want 1", calls)
+			}
+			if got := metrics["App0"].MRR; got != 900 {
+				t.Fatalf("App0 MRR = %d, want 900", got)
+			}
+			if productCount > 1 {
+				if got := metrics["App1"].Subscribers; got != 1 {
+					t.Fatalf("App1 subscribers = %d, want 1", got)
+				}
+			}
+			for name, m := range metrics {
+				if len(m.Errors) > 0 {
+					t.Fatalf("%s errors = %v, want none", name, m.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchEachStreamsUpdates(t *testing.T) {
+	var calls int32
+	page := `{"has_more": false, "data": [{"id": "sub_1", "status": "active", "items": {"data": [{"price": {"product": "prod_slow", "unit_amount": 900}}]}}]}`
+	stripeServer := newStripeTestServer(t, []string{page}, "", &calls)
+	stripe := NewStripeClient("sk_test")
+	stripe.baseURL = stripeServer.URL
+
+	// Sentry answers only once the fast product has been delivered, so the
+	// test deadlocks (and times out) if FetchEach waits for every product.
+	release := make(chan struct{})
+	sentryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+			t.Errorf("sentry request was not released by the fast product's final update")
+		}
+		w.Header().Set("X-Hits", "4")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer sentryServer.Close()
+	sentry := NewSentryClient("tok", "org", sentryServer.URL)
+
+	products := []domain.Product{
+		{Name: "Fast"},
+		{Name: "Slow", StripeID: "prod_slow", SentryProject: "slow"},
+	}
+
+	var got []string
+	fetcher := NewMetricsFetcher(newTestRegistry(t, stripe, sentry), nil)
+	fetcher.FetchEach(context.Background(), products, func(u Update) {
+		event := u.ProductName + ":" + u.Provider
+		if u.Done {
+			event = u.ProductName + ":done"
+		}
+		got = append(got, event)
+
+		switch {
+		case u.ProductName == "Fast" && u.Done:
+			close(release)
+		case u.ProductName == "Slow" && u.Provider == ProviderStripe:
+			if u.Metrics.MRR != 900 || u.Metrics.UnresolvedIssues != 0 {
+				t.Errorf("stripe partial = %+v, want MRR only", u.Metrics)
+			}
+		case u.ProductName == "Slow" && u.Done:
+			if u.Metrics.MRR != 900 || u.Metrics.UnresolvedIssues != 4 {
+				t.Errorf("final update = %+v, want MRR and issues", u.Metrics)
+			}
+		}
+	})
+
+	var slow []string
+	for _, event := range got {
+		if strings.HasPrefix(event, "Slow:") {
+			slow = append(slow, event)
+		}
+	}
+	if want := []string{"Slow:stripe", "Slow:sentry", "Slow:done"}; !reflect.DeepEqual(slow, want) {
+		t.Errorf("Slow updates = %v, want %v", slow, want)
+	}
+	if len(got) != 4 {
+		t.Errorf("updates = %v, want Fast:done plus three for Slow", got)
+	}
+}
+
+func TestFetchEachRunsProvidersConcurrently(t *testing.T) {
+	var calls int32
+	page := `{"has_more": false, "data": [{"id": "sub_1", "status": "active", "items": {"data": [{"price": {"product": "prod_a", "unit_amount": 900}}]}}]}`
+	stripeServer := newStripeTestServer(t, []string{page}, "", &calls)
+	stripe := NewStripeClient("sk_test")
+	stripe.baseURL = stripeServer.URL
+
+	// Sentry runs first but answers only once Stripe's result has been
+	// delivered, so the test times out if a product's providers run in turn.
+	release := make(chan struct{})
+	sentryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+			t.Errorf("sentry request was not released by stripe's update")
+		}
+		w.Header().Set("X-Hits", "4")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer sentryServer.Close()
+	sentry := NewSentryClient("tok", "org", sentryServer.URL)
+
+	products := []domain.Product{{Name: "App", StripeID: "prod_a", SentryProject: "app"}}
+
+	var got []string
+	fetcher := NewMetricsFetcher(newTestRegistry(t, sentry, stripe), nil)
+	fetcher.FetchEach(context.Background(), products, func(u Update) {
+		got = append(got, u.Provider)
+		switch {
+		case u.Provider == ProviderStripe:
+			close(release)
+		case u.Done:
+			if u.Metrics.MRR != 900 || u.Metrics.UnresolvedIssues != 4 {
+				t.Errorf("final update = %+v, want MRR and issues", u.Metrics)
+			}
+		}
+	})
+	if want := []string{ProviderStripe, ProviderSentry, ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("update providers = %v, want %v", got, want)
+	}
+}
+
+func TestBuildDailyHistory(t *testing.T) {
+	now := time.Date(2026, 1, 7, 15, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	values := []domain.DailyValue{
+		{Day: day(1), Metric: domain.DailyVisits, Value: 99},
+		{Day: day(5), Metric: domain.DailyVisits, Value: 20},
+		{Day: day(7), Metric: domain.DailyVisits, Value: 40},
+	}
+
+	got := buildDailyHistory(values, now, 4)
+	want := []int64{0, 20, 0, 40}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("buildDailyHistory() = %v, want %v", got, want)
+	}
+	if got := buildDailyHistory(values, now, 0); got != nil {
+		t.Fatalf("buildDailyHistory(days=0) = %v, want nil", got)
+	}
+}
+
+func TestFetchAllRecordsDailyMetrics(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "toStartOfDay") {
+			today := time.Now().UTC().Format("2006-01-02")
+			_, _ = w.Write([]byte(`{"results": [["app.com", "` + today + `", 12, 7]]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"results": [["app.com", 80, 30]]}`))
+	}))
+	defer server.Close()
+
+	posthog := NewPostHogClient("key", "proj", server.URL)
+	posthog.client = server.Client()
+
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	fetcher := NewMetricsFetcher(newTestRegistry(t, posthog), s)
+	metrics := fetcher.FetchAll(context.Background(), []domain.Product{{Name: "App", PostHogHost: "app.com"}})
+
+	got := metrics["App"]
+	if got == nil || len(got.Errors) != 0 {
+		t.Fatalf("FetchAll() = %#v, want metrics without errors", got)
+	}
+	if got.Visits != 80 {
+		t.Errorf("Visits = %d, want weekly total 80", got.Visits)
+	}
+	want := []int64{0, 0, 0, 0, 0, 0, 12}
+	if !reflect.DeepEqual(got.VisitsHistory, want) {
+		t.Errorf("VisitsHistory = %v, want %v", got.VisitsHistory, want)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		today := time.Now().UTC().Format("2006-01-02")
+		_, _ = w.Write([]byte(`{"results": [["app.com", "` + today + `", 12, 7]]}`))
+	}))
+	defer server.Close()
+
+	posthog := NewPostHogClient("key", "proj", server.URL)
+	posthog.client = server.Client()
+
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	products := []domain.Product{
+		{Name: "App", PostHogHost: "app.com"},
+		{Name: "NoTraffic"},
+	}
+	fetcher := NewMetricsFetcher(newTestRegistry(t, posthog), s)
+	written, err := fetcher.Backfill(context.Background(), products, 3)
+	if err != nil {
+		t.Fatalf("Backfill() error = %v", err)
+	}
+	if !reflect.DeepEqual(written, map[string]int{"App": 3}) {
+		t.Fatalf("Backfill() written = %v, want App: 3", written)
+	}
+
+	now := time.Now()
+	daily, err := s.GetDailyMetrics(context.Background(), "App", now.AddDate(0, 0, -2), now)
+	if err != nil {
+		t.Fatalf("GetDailyMetrics() error = %v", err)
+	}
+	visits, uniques := daily[domain.DailyVisits], daily[domain.DailyUniques]
+	if len(visits) != 3 || visits[0].Value != 0 || visits[2].Value != 12 || uniques[2].Value != 7 {
+		t.Fatalf("GetDailyMetrics() = %#v, want zero-filled days ending with today's traffic", daily)
+	}
+
+	if _, err := fetcher.Backfill(context.Background(), products, 0); err == nil {
+		t.Fatalf("Backfill(days=0) error = nil, want error")
+	}
+}
+
+func TestLoadCached(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	ctx := context.Background()
+	saved := &domain.Metrics{ProductName: "App", Timestamp: time.Now().Add(-3 * time.Hour), Visits: 42, MRR: 1299}
+	if err := s.SaveMetrics(ctx, saved); err != nil {
+		t.Fatalf("SaveMetrics() error = %v", err)
+	}
+	if err := s.SaveDailyMetrics(ctx, "App", []domain.DailyValue{{Day: time.Now(), Metric: domain.DailyVisits, Value: 6}}); err != nil {
+		t.Fatalf("SaveDailyMetrics() error = %v", err)
+	}
+
+	// No provider clients: any network access would panic.
+	fetcher := NewMetricsFetcher(nil, s)
+	cached, err := fetcher.LoadCached(ctx, []domain.Product{{Name: "App"}, {Name: "New"}})
+	if err != nil {
+		t.Fatalf("LoadCached() error = %v", err)
+	}
+
+	if _, ok := cached["New"]; ok {
+		t.Errorf("LoadCached() has entry for product without snapshots")
+	}
+	got := cached["App"]
+	if got == nil || !got.Stale || got.Visits != 42 || got.MRR != 1299 {
+		t.Fatalf("LoadCached()[App] = %#v, want stale cached snapshot", got)
+	}
+	if len(got.VisitsHistory) != trendDays || got.VisitsHistory[trendDays-1] != 6 {
+		t.Errorf("VisitsHistory = %v, want daily series ending in 6", got.VisitsHistory)
+	}
+
+	empty, err := NewMetricsFetcher(nil, nil).LoadCached(ctx, []domain.Product{{Name: "App"}})
+	if err != nil || len(empty) != 0 {
+		t.Errorf("LoadCached() without store = %v, %v, want empty", empty, err)
+	}
+}
+
+func TestUptime(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, snap := range []domain.Metrics{
+		{ProductName: "App", Timestamp: now.AddDate(0, 0, -10), HealthStatus: "down"},
+		{ProductName: "App", Timestamp: now.Add(-90 * time.Minute), HealthStatus: "healthy"},
+		{ProductName: "App", Timestamp: now.Add(-30 * time.Minute), HealthStatus: "down"},
+	} {
+		if err := s.SaveMetrics(ctx, &snap); err != nil {
+			t.Fatalf("SaveMetrics() error = %v", err)
+		}
+	}
+
+	uptime, err := NewMetricsFetcher(nil, s).Uptime(ctx, []domain.Product{{Name: "App"}, {Name: "New"}}, now)
+	if err != nil {
+		t.Fatalf("Uptime() error = %v", err)
+	}
+	want := []domain.Uptime{
+		{Window: 24 * time.Hour, Up: time.Hour, Down: 30 * time.Minute},
+		{Window: 7 * 24 * time.Hour, Up: time.Hour, Down: 30 * time.Minute},
+		{Window: 30 * 24 * time.Hour, Up: time.Hour, Down: 90 * time.Minute},
+	}
+	if !reflect.DeepEqual(uptime["App"], want) {
+		t.Errorf("Uptime()[App] = %+v, want %+v", uptime["App"], want)
+	}
+	for _, u := range uptime["New"] {
+		if _, ok := u.Percent(); ok {
+			t.Errorf("Uptime()[New] = %+v, want nothing monitored", uptime["New"])
+		}
+	}
+
+	// Snapshots every 10 minutes hold a status for 20.
+	fetcher := NewMetricsFetcher(nil, s)
+	fetcher.SetSnapshotInterval(10 * time.Minute)
+	uptime, err = fetcher.Uptime(ctx, []domain.Product{{Name: "App"}}, now)
+	if err != nil {
+		t.Fatalf("Uptime() error = %v", err)
+	}
+	want = []domain.Uptime{
+		{Window: 24 * time.Hour, Up: 20 * time.Minute, Down: 20 * time.Minute},
+		{Window: 7 * 24 * time.Hour, Up: 20 * time.Minute, Down: 20 * time.Minute},
+		{Window: 30 * 24 * time.Hour, Up: 20 * time.Minute, Down: 40 * time.Minute},
+	}
+	if !reflect.DeepEqual(uptime["App"], want) {
+		t.Errorf("Uptime()[App] with a 10m interval = %+v, want %+v", uptime["App"], want)
+	}
+}
+
+func TestIncidents(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour)
+	if err := s.TrackIncident(ctx, "Removed", "down", "status 500", start); err != nil {
+		t.Fatalf("TrackIncident() error = %v", err)
+	}
+
+	products := []domain.Product{
+		{Name: "App", Domain: "127.0.0.1:1"},
+		{Name: "NoHealth"},
+	}
+	fetcher := NewMetricsFetcher(newTestRegistry(t, NewHealthChecker(s)), s)
+	for range 2 {
+		fetcher.FetchAll(ctx, products)
+	}
+
+	incidents, err := fetcher.Incidents(ctx, products, start)
+	if err != nil {
+		t.Fatalf("Incidents() error = %v", err)
+	}
+	if len(incidents) != 1 {
+		t.Fatalf("Incidents() = %+v, want one for App", incidents)
+	}
+	if got := incidents[0]; got.ProductName != "App" || got.Status != "down" || !got.Ongoing() || got.Reason == "" {
+		t.Errorf("Incidents()[0] = %+v, want App down and ongoing with a reason", got)
+	}
+}
+
+func TestDailyUptime(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+	snap := &domain.Metrics{ProductName: "App", Timestamp: now.Add(-30 * time.Minute), HealthStatus: "down"}
+	if err := s.SaveMetrics(ctx, snap); err != nil {
+		t.Fatalf("SaveMetrics() error = %v", err)
+	}
+
+	uptime, err := NewMetricsFetcher(nil, s).DailyUptime(ctx, []domain.Product{{Name: "App"}}, now, 90)
+	if err != nil {
+		t.Fatalf("DailyUptime() error = %v", err)
+	}
+	days := uptime["App"]
+	if len(days) != 90 {
+		t.Fatalf("DailyUptime() days = %d, want 90", len(days))
+	}
+	if today := days[89]; today.Window != 6*time.Hour || today.Down != 30*time.Minute {
+		t.Errorf("today = %+v, want 6h window with 30m down", today)
+	}
+	if _, ok := days[88].Percent(); ok {
+		t.Errorf("yesterday = %+v, want nothing monitored", days[88])
+	}
+}
+
+func TestMRRMovement(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	from := now.AddDate(0, 0, -6)
+	day := func(daysAgo int) time.Time { return domain.StartOfDayUTC(now).AddDate(0, 0, -daysAgo) }
+	values := map[string][]domain.DailyValue{
+		"App": {
+			{Day: day(7), Metric: domain.DailyMRR, Value: 10000},
+			{Day: day(7), Metric: domain.DailyNewMRR, Value: 9999}, // before the period
+			{Day: day(5), Metric: domain.DailyNewMRR, Value: 2000},
+			{Day: day(5), Metric: domain.DailyNewSubscriptions, Value: 2},
+			{Day: day(3), Metric: domain.DailyChurnedMRR, Value: 500},
+			{Day: day(3), Metric: domain.DailyCanceledSubscriptions, Value: 1},
+			{Day: day(1), Metric: domain.DailyExpansionMRR, Value: 300},
+			{Day: day(0), Metric: domain.DailyContractionMRR, Value: 100},
+			{Day: day(0), Metric: domain.DailyMRR, Value: 11900},
+		},
+		"Fresh": {
+			{Day: day(2), Metric: domain.DailyNewMRR, Value: 1000},
+			{Day: day(0), Metric: domain.DailyMRR, Value: 5000},
+		},
+	}
+	for name, v := range values {
+		if err := s.SaveDailyMetrics(ctx, name, v); err != nil {
+			t.Fatalf("SaveDailyMetrics() error = %v", err)
+		}
+	}
+
+	products := []domain.Product{{Name: "App", StripeID: "prod_a"}, {Name: "Fresh", StripeID: "prod_f"}, {Name: "Free"}}
+	got, err := NewMetricsFetcher(nil, s).MRRMovement(ctx, products, from, now)
+	if err != nil {
+		t.Fatalf("MRRMovement() error = %v", err)
+	}
+	want := map[string]domain.MRRMovement{
+		"App": {
+			From: day(6), To: now, StartMRR: 10000, EndMRR: 11900,
+			New: 2000, Expansion: 300, Contraction: 100, Churned: 500,
+			NewSubscriptions: 2, CanceledSubscriptions: 1,
+		},
+		"Fresh": {From: day(6), To: now, StartMRR: 4000, EndMRR: 5000, New: 1000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MRRMovement() = %+v, want %+v", got, want)
+	}
+	if other := got["App"].Other(); other != 200 {
+		t.Errorf("Other() = %d, want 200", other)
+	}
+}
@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+const githubBaseURL = "https://api.github.com"
+
+type GitHubClient struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGitHubClient creates a GitHub client. The token is optional; without one
+// requests are unauthenticated and subject to GitHub's lower rate limit.
+func NewGitHubClient(token string) *GitHubClient {
+	return &GitHubClient{
+		token:      token,
+		baseURL:    githubBaseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type GitHubActivity struct {
+	Commits    int64  // commits on the default branch since `since`
+	OpenIssues int64  // open issues, excluding pull requests
+	OpenPRs    int64  // open pull requests
+	Stars      int64  // stargazers
+	CIStatus   string // conclusion of the latest completed default-branch workflow run
+}
+
+type githubRepo struct {
+	StargazersCount int64  `json:"stargazers_count"`
+	OpenIssuesCount int64  `json:"open_issues_count"` // includes pull requests
+	DefaultBranch   string `json:"default_branch"`
+}
+
+type githubWorkflowRuns struct {
+	WorkflowRuns []struct {
+		Conclusion string `json:"conclusion"`
+	} `json:"workflow_runs"`
+}
+
+// GetActivity returns shipping activity for a repository in "owner/name" form.
+func (c *GitHubClient) GetActivity(ctx context.Context, repo string, since time.Time) (*GitHubActivity, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("github: repo %q must be owner/name", repo)
+	}
+	base := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, url.PathEscape(owner), url.PathEscape(name))
+
+	var info githubRepo
+	if _, err := c.getJSON(ctx, base, &info); err != nil {
+		return nil, fmt.Errorf("github: get repo: %w", err)
+	}
+
+	openPRs, err := c.count(ctx, base+"/pulls?state=open&per_page=1")
+	if err != nil {
+		return nil, fmt.Errorf("github: count pull requests: %w", err)
+	}
+
+	commitParams := url.Values{}
+	commitParams.Set("since", since.UTC().Format(time.RFC3339))
+	commitParams.Set("per_page", "1")
+	if info.DefaultBranch != "" {
+		commitParams.Set("sha", info.DefaultBranch)
+	}
+	commits, err := c.count(ctx, base+"/commits?"+commitParams.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("github: count commits: %w", err)
+	}
+
+	runParams := url.Values{}
+	runParams.Set("status", "completed")
+	runParams.Set("per_page", "1")
+	if info.DefaultBranch != "" {
+		runParams.Set("branch", info.DefaultBranch)
+	}
+	var runs githubWorkflowRuns
+	if _, err := c.getJSON(ctx, base+"/actions/runs?"+runParams.Encode(), &runs); err != nil {
+		return nil, fmt.Errorf("github: list workflow runs: %w", err)
+	}
+
+	activity := &GitHubActivity{
+		Commits:    commits,
+		OpenIssues: max(0, info.OpenIssuesCount-openPRs),
+		OpenPRs:    openPRs,
+		Stars:      info.StargazersCount,
+	}
+	if len(runs.WorkflowRuns) > 0 {
+		activity.CIStatus = runs.WorkflowRuns[0].Conclusion
+	}
+	return activity, nil
+}
+
+// count returns the number of items in a per_page=1 list endpoint by reading
+// the page number of the rel="last" link, avoiding a walk over every page.
+func (c *GitHubClient) count(ctx context.Context, endpoint string) (int64, error) {
+	var items []json.RawMessage
+	header, err := c.getJSON(ctx, endpoint, &items)
+	if err != nil {
+		return 0, err
+	}
+	if last := lastGitHubPage(header.Get("Link")); last > 0 {
+		return last, nil
+	}
+	return int64(len(items)), nil
+}
+
+func (c *GitHubClient) getJSON(ctx context.Context, endpoint string, out any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusConflict {
+		// Empty repositories return 409 for commit and run listings.
+		return resp.Header, nil
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// lastGitHubPage extracts the page number from the rel="last" entry of a
+// GitHub Link header, or 0 when there is no last page.
+func lastGitHubPage(link string) int64 {
+	for _, part := range strings.Split(link, ",") {
+		part = strings.TrimSpace(part)
+		if !strings.HasSuffix(part, `rel="last"`) {
+			continue
+		}
+		start := strings.Index(part, "<")
+		end := strings.Index(part, ">")
+		if start == -1 || end <= start {
+			continue
+		}
+		u, err := url.Parse(part[start+1 : end])
+		if err != nil {
+			continue
+		}
+		page, err := strconv.ParseInt(u.Query().Get("page"), 10, 64)
+		if err != nil {
+			continue
+		}
+		return page
+	}
+	return 0
+}
+
+func (c *GitHubClient) Name() string  { return ProviderGitHub }
+func (c *GitHubClient) Label() string { return "GitHub" }
+
+func (c *GitHubClient) Enabled(p domain.Product) bool { return p.GitHubRepo != "" }
+
+func (c *GitHubClient) Begin(_ context.Context, _ []domain.Product, w Window) CollectFunc {
+	return func(ctx context.Context, p domain.Product, s *Sample) error {
+		activity, err := c.GetActivity(ctx, p.GitHubRepo, w.WeekAgo)
+		if err != nil {
+			return err
+		}
+		s.Metrics.Commits = activity.Commits
+		s.Metrics.OpenIssues = activity.OpenIssues
+		s.Metrics.OpenPRs = activity.OpenPRs
+		s.Metrics.Stars = activity.Stars
+		s.Metrics.CIStatus = activity.CIStatus
+		return nil
+	}
+}
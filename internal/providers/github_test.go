@@ -0,0 +1,115 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubGetActivity(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		switch r.URL.Path {
+		case "/repos/acme/app":
+			_, _ = w.Write([]byte(`{"stargazers_count": 42, "open_issues_count": 10, "default_branch": "main"}`))
+		case "/repos/acme/app/pulls":
+			w.Header().Set("Link", `<`+server.URL+`/repos/acme/app/pulls?state=open&per_page=1&page=2>; rel="next", <`+server.URL+`/repos/acme/app/pulls?state=open&per_page=1&page=4>; rel="last"`)
+			_, _ = w.Write([]byte(`[{}]`))
+		case "/repos/acme/app/commits":
+			if r.URL.Query().Get("sha") != "main" || r.URL.Query().Get("since") == "" {
+				t.Errorf("commits query = %q, want sha=main and since", r.URL.RawQuery)
+			}
+			w.Header().Set("Link", `<`+server.URL+`/repos/acme/app/commits?per_page=1&page=17>; rel="last"`)
+			_, _ = w.Write([]byte(`[{}]`))
+		case "/repos/acme/app/actions/runs":
+			if r.URL.Query().Get("branch") != "main" {
+				t.Errorf("runs query = %q, want branch=main", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"workflow_runs": [{"conclusion": "failure"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("tok")
+	client.baseURL = server.URL
+
+	got, err := client.GetActivity(context.Background(), "acme/app", time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("GetActivity() error = %v", err)
+	}
+	want := GitHubActivity{Commits: 17, OpenIssues: 6, OpenPRs: 4, Stars: 42, CIStatus: "failure"}
+	if *got != want {
+		t.Fatalf("GetActivity() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestGitHubGetActivityEmptyRepo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/empty":
+			_, _ = w.Write([]byte(`{"stargazers_count": 1, "open_issues_count": 0, "default_branch": "main"}`))
+		case "/repos/acme/empty/pulls":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.Error(w, `{"message":"Git Repository is empty."}`, http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("")
+	client.baseURL = server.URL
+
+	got, err := client.GetActivity(context.Background(), "acme/empty", time.Now())
+	if err != nil {
+		t.Fatalf("GetActivity() error = %v", err)
+	}
+	want := GitHubActivity{Stars: 1}
+	if *got != want {
+		t.Fatalf("GetActivity() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestGitHubGetActivityErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("")
+	client.baseURL = server.URL
+
+	if _, err := client.GetActivity(context.Background(), "acme/missing", time.Now()); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("GetActivity() error = %v, want status 404", err)
+	}
+	if _, err := client.GetActivity(context.Background(), "not-a-repo", time.Now()); err == nil {
+		t.Fatalf("GetActivity() with bad repo error = nil, want error")
+	}
+}
+
+func TestLastGitHubPage(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want int64
+	}{
+		{name: "last present", link: `<https://api.github.com/x?page=2>; rel="next", <https://api.github.com/x?per_page=1&page=9>; rel="last"`, want: 9},
+		{name: "no last", link: `<https://api.github.com/x?page=1>; rel="prev"`, want: 0},
+		{name: "empty", link: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastGitHubPage(tt.link); got != tt.want {
+				t.Errorf("lastGitHubPage() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
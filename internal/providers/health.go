@@ -1,61 +1,562 @@
 package providers
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/store"
 )
 
 type HealthResult struct {
 	Status       string // "healthy", "degraded", "down"
-	ResponseTime int64  // milliseconds
+	ResponseTime int64  // milliseconds, through the end of the body
 	StatusCode   int
+	Reason       string // why Status isn't "healthy"; empty when it is
+	TLS          *TLSInfo
+	Timings      Timings
+}
+
+// Timings breaks a probe's ResponseTime into request phases. Across
+// redirects each phase is the sum over every request. A failed probe keeps
+// the phases it got through.
+type Timings struct {
+	DNS      time.Duration
+	Connect  time.Duration // TCP
+	TLS      time.Duration // handshake
+	TTFB     time.Duration // request written to first response byte
+	Download time.Duration // first byte to end of body
+}
+
+// phaseTrace records Timings through httptrace hooks, which the transport
+// may call from its own goroutines.
+type phaseTrace struct {
+	mu           sync.Mutex
+	timings      Timings
+	dnsStart     time.Time
+	connectStart map[string]time.Time // by address; dialing may race IPv4 and IPv6
+	tlsStart     time.Time
+	wrote        time.Time
+	firstByte    time.Time
+}
+
+func (p *phaseTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timings.DNS += time.Since(p.dnsStart)
+		},
+		ConnectStart: func(_, addr string) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if p.connectStart == nil {
+				p.connectStart = make(map[string]time.Time)
+			}
+			p.connectStart[addr] = time.Now()
+		},
+		ConnectDone: func(_, addr string, err error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if err == nil {
+				p.timings.Connect += time.Since(p.connectStart[addr])
+			}
+		},
+		TLSHandshakeStart: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.timings.TLS += time.Since(p.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.wrote = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.firstByte = time.Now()
+			if !p.wrote.IsZero() {
+				p.timings.TTFB += p.firstByte.Sub(p.wrote)
+			}
+		},
+	}
+}
+
+// finish returns the phases, counting the download from the final
+// response's first byte to now.
+func (p *phaseTrace) finish() Timings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	timings := p.timings
+	if !p.firstByte.IsZero() {
+		timings.Download = time.Since(p.firstByte)
+	}
+	return timings
+}
+
+// TLSInfo describes the certificate a health check was served. It is nil
+// for plain HTTP and for connections that failed before the handshake.
+type TLSInfo struct {
+	ExpiresAt time.Time // leaf certificate NotAfter
+	Issuer    string
+	Error     string // why verification failed; empty when it passed
 }
 
-// CheckHealth performs an HTTP GET to the domain and returns health status.
-func CheckHealth(ctx context.Context, domain string) (*HealthResult, error) {
-	if domain == "" {
+// defaultHealthTimeout bounds a check with no configured timeout.
+const defaultHealthTimeout = 5 * time.Second
+
+// healthMaxBody caps how much of a response body assertions look at.
+const healthMaxBody = 1 << 20
+
+// healthRetryDelay is the pause before retrying a probe that found the site down.
+const healthRetryDelay = time.Second
+
+// CheckHealth requests https://<host><path> as check describes and classifies
+// the responses. A nil check sends a single GET / and classifies by status
+// range only. CheckHealth knows nothing of earlier checks; HealthChecker
+// applies DownAfter.
+func CheckHealth(ctx context.Context, host string, check *domain.HealthCheck) (*HealthResult, error) {
+	if host == "" {
 		return nil, fmt.Errorf("health: domain is empty")
 	}
+	if check == nil {
+		check = &domain.HealthCheck{}
+	}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	url := "https://" + domain
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+	path := check.Path
+	if path == "" {
+		path = "/"
+	}
+	// A fresh connection per probe, so every probe times DNS, TCP and TLS
+	// rather than reusing a connection from an earlier check.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	client := &http.Client{Timeout: timeout, Transport: transport}
+	return runHealthCheck(ctx, client, "https://"+host+path, check, healthRetryDelay)
+}
+
+// runHealthCheck sends check.Probes probes one after another, retrying each
+// that finds the site down up to check.Retries times, and summarizes them.
+func runHealthCheck(ctx context.Context, client *http.Client, target string, check *domain.HealthCheck, retryDelay time.Duration) (*HealthResult, error) {
+	probes := max(check.Probes, 1)
+	results := make([]*HealthResult, 0, probes)
+	for i := 0; i < probes; i++ {
+		var result *HealthResult
+		for attempt := 0; ; attempt++ {
+			var err error
+			if result, err = probeHealth(ctx, client, target, check); err != nil {
+				return nil, err
+			}
+			if result.Status != "down" || attempt >= check.Retries || !sleepContext(ctx, retryDelay) {
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return summarizeProbes(results, check.DegradedOver), nil
+}
+
+// summarizeProbes reports the median latency and the status most probes
+// agree on: down only when a majority were down, and degraded when any probe
+// failed or the median latency is over degradedOver.
+func summarizeProbes(results []*HealthResult, degradedOver time.Duration) *HealthResult {
+	latencies := make([]int64, len(results))
+	var failures []*HealthResult
+	var downs int
+	for i, r := range results {
+		latencies[i] = r.ResponseTime
+		if r.Status != "healthy" {
+			failures = append(failures, r)
+		}
+		if r.Status == "down" {
+			downs++
+		}
+	}
+	slices.Sort(latencies)
+	median := latencies[len(latencies)/2]
+	if len(latencies)%2 == 0 {
+		median = (latencies[len(latencies)/2-1] + median) / 2
+	}
 
+	var summary HealthResult
+	if len(failures) == 0 {
+		summary = *results[len(results)-1]
+		if degradedOver > 0 && median > degradedOver.Milliseconds() {
+			summary.Status = "degraded"
+			summary.Reason = fmt.Sprintf("slow: %dms over %s", median, degradedOver)
+		}
+	} else {
+		summary = *failures[len(failures)-1]
+		summary.Status = "degraded"
+		if downs*2 > len(results) {
+			summary.Status = "down"
+		}
+		if len(results) > 1 {
+			summary.Reason = fmt.Sprintf("%d of %d probes failed: %s", len(failures), len(results), summary.Reason)
+		}
+	}
+	summary.ResponseTime = median
+	summary.Timings = medianProbe(results).Timings
+	for _, r := range results {
+		if r.TLS != nil {
+			summary.TLS = r.TLS
+		}
+	}
+	return &summary
+}
+
+// medianProbe returns the probe with the median latency, the upper one of
+// an even count, so the reported phases come from a single request.
+func medianProbe(results []*HealthResult) *HealthResult {
+	sorted := slices.Clone(results)
+	slices.SortStableFunc(sorted, func(a, b *HealthResult) int {
+		return cmp.Compare(a.ResponseTime, b.ResponseTime)
+	})
+	return sorted[len(sorted)/2]
+}
+
+// sleepContext waits for d and reports whether ctx is still live.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// probeHealth sends one request and classifies it by status and body.
+func probeHealth(ctx context.Context, client *http.Client, target string, check *domain.HealthCheck) (*HealthResult, error) {
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	trace := &phaseTrace{}
 	start := time.Now()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace.clientTrace()), method, target, nil)
 	if err != nil {
 		return nil, fmt.Errorf("health: build request: %w", err)
 	}
+	for key, value := range check.Headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := client.Do(req)
-	elapsed := time.Since(start).Milliseconds()
 	if err != nil {
+		elapsed := time.Since(start).Milliseconds()
 		// Network errors (DNS failure, timeout, connection refused) mean the site is down.
 		// Return nil error because "down" is a valid health check result, not an error condition.
+		reason := err.Error()
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			reason = urlErr.Err.Error()
+		}
+		info := certErrorInfo(err)
+		if info != nil {
+			reason = "certificate " + info.Error
+		}
 		return &HealthResult{
 			Status:       "down",
 			ResponseTime: elapsed,
 			StatusCode:   0,
+			Reason:       reason,
+			TLS:          info,
+			Timings:      trace.finish(),
 		}, nil
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
-	var status string
+	// Read the body here rather than only for assertions, so the download
+	// is part of the timing.
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, healthMaxBody))
+	result := &HealthResult{
+		Status:       "healthy",
+		ResponseTime: time.Since(start).Milliseconds(),
+		StatusCode:   resp.StatusCode,
+		Timings:      trace.finish(),
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		result.TLS = certInfo(resp.TLS.PeerCertificates[0])
+	}
 	switch {
+	case len(check.ExpectStatus) > 0:
+		if !slices.Contains(check.ExpectStatus, resp.StatusCode) {
+			result.Status = "down"
+			result.Reason = fmt.Sprintf("status %d, want %s", resp.StatusCode, joinInts(check.ExpectStatus))
+		}
 	case resp.StatusCode >= 200 && resp.StatusCode < 400:
-		status = "healthy"
 	case resp.StatusCode >= 400 && resp.StatusCode < 500:
-		status = "degraded"
+		result.Status = "degraded"
+		result.Reason = fmt.Sprintf("status %d", resp.StatusCode)
 	default:
-		status = "down"
+		result.Status = "down"
+		result.Reason = fmt.Sprintf("status %d", resp.StatusCode)
+	}
+	if result.Status != "healthy" {
+		return result, nil
+	}
+	if readErr != nil {
+		result.Status = "down"
+		result.Reason = fmt.Sprintf("read body: %v", readErr)
+		return result, nil
 	}
 
-	return &HealthResult{
-		Status:       status,
-		ResponseTime: elapsed,
-		StatusCode:   resp.StatusCode,
-	}, nil
+	reason, err := checkBody(body, check)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		result.Status = "down"
+		result.Reason = reason
+	}
+	return result, nil
+}
+
+func certInfo(cert *x509.Certificate) *TLSInfo {
+	issuer := cert.Issuer.CommonName
+	if issuer == "" && len(cert.Issuer.Organization) > 0 {
+		issuer = cert.Issuer.Organization[0]
+	}
+	return &TLSInfo{ExpiresAt: cert.NotAfter, Issuer: issuer}
+}
+
+// certErrorInfo describes the certificate behind a failed verification, or
+// returns nil if err isn't one.
+func certErrorInfo(err error) *TLSInfo {
+	var (
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+		unknownErr x509.UnknownAuthorityError
+		info       *TLSInfo
+	)
+	switch {
+	case errors.As(err, &hostErr) && hostErr.Certificate != nil:
+		info = certInfo(hostErr.Certificate)
+		info.Error = "hostname mismatch: " + hostErr.Error()
+	case errors.As(err, &invalidErr) && invalidErr.Cert != nil:
+		info = certInfo(invalidErr.Cert)
+		info.Error = "invalid: " + invalidErr.Error()
+		if invalidErr.Reason == x509.Expired {
+			info.Error = "expired " + invalidErr.Cert.NotAfter.UTC().Format("2006-01-02")
+		}
+	case errors.As(err, &unknownErr) && unknownErr.Cert != nil:
+		info = certInfo(unknownErr.Cert)
+		info.Error = "untrusted: " + unknownErr.Error()
+	}
+	return info
+}
+
+// checkBody returns why data, the start of the body, fails check's
+// assertions, or "" if it passes.
+func checkBody(data []byte, check *domain.HealthCheck) (string, error) {
+	if check.BodyContains != "" && !bytes.Contains(data, []byte(check.BodyContains)) {
+		return fmt.Sprintf("body missing %q", check.BodyContains), nil
+	}
+	if check.BodyMatches != nil && !check.BodyMatches.Match(data) {
+		return fmt.Sprintf("body doesn't match /%s/", check.BodyMatches), nil
+	}
+	if len(check.JSON) == 0 {
+		return "", nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return "body is not JSON", nil
+	}
+	for _, assert := range check.JSON {
+		got, err := lookupJSONPath(doc, assert.Path)
+		if err != nil {
+			return err.Error(), nil
+		}
+		if !jsonEqual(got, assert.Equals) {
+			return fmt.Sprintf("%s is %s, want %s", assert.Path, formatJSON(got), formatJSON(assert.Equals)), nil
+		}
+	}
+	return "", nil
+}
+
+// jsonEqual compares a decoded JSON scalar with a value from the config.
+// Numbers compare by value, so 1 matches 1.0.
+func jsonEqual(got, want any) bool {
+	if want == nil || got == nil {
+		return want == nil && got == nil
+	}
+	if wantNum, ok := toFloat(want); ok {
+		gotNum, ok := got.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := gotNum.Float64()
+		return err == nil && f == wantNum
+	}
+	switch want := want.(type) {
+	case string:
+		s, ok := got.(string)
+		return ok && s == want
+	case bool:
+		b, ok := got.(bool)
+		return ok && b == want
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func formatJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, "/")
+}
+
+// HealthChecker is the Provider for CheckHealth. With a store it counts
+// consecutive failed checks per product, and reports a failing product as
+// degraded until its check's DownAfter failures in a row.
+type HealthChecker struct {
+	store *store.Store
+}
+
+// NewHealthChecker returns a HealthChecker that tracks failures in s, which
+// may be nil to report every failed check as down.
+func NewHealthChecker(s *store.Store) HealthChecker {
+	return HealthChecker{store: s}
+}
+
+func (HealthChecker) Name() string  { return ProviderHealth }
+func (HealthChecker) Label() string { return "Health" }
+
+func (HealthChecker) Enabled(p domain.Product) bool { return p.Domain != "" }
+
+// ValidateProduct checks the JSON assertion paths; the config package
+// validates everything else.
+func (HealthChecker) ValidateProduct(p domain.Product) error {
+	if p.Health == nil {
+		return nil
+	}
+	for _, assert := range p.Health.JSON {
+		if _, err := parseJSONPath(assert.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c HealthChecker) Begin(_ context.Context, _ []domain.Product, w Window) CollectFunc {
+	return func(ctx context.Context, p domain.Product, s *Sample) error {
+		health, err := CheckHealth(ctx, p.Domain, p.Health)
+		if err != nil {
+			return err
+		}
+
+		// Without a failure count every failed check is down, as if DownAfter were 1.
+		var storeErr error
+		if c.store != nil {
+			failures, err := c.store.RecordHealthCheck(ctx, p.Name, health.Status == "down", w.Now)
+			downAfter := healthDownAfter(p.Health)
+			switch {
+			case err != nil:
+				storeErr = err
+			case health.Status == "down" && failures < downAfter:
+				health.Status = "degraded"
+				health.Reason = fmt.Sprintf("failed %d of %d checks before down: %s", failures, downAfter, health.Reason)
+			}
+		}
+
+		applyCert(s.Metrics, health, p.Health, w.Now)
+		s.Metrics.HealthStatus = health.Status
+		s.Metrics.ResponseTime = health.ResponseTime
+		s.Metrics.HealthReason = health.Reason
+		s.Metrics.DNSTime = health.Timings.DNS.Milliseconds()
+		s.Metrics.ConnectTime = health.Timings.Connect.Milliseconds()
+		s.Metrics.TLSTime = health.Timings.TLS.Milliseconds()
+		s.Metrics.TTFB = health.Timings.TTFB.Milliseconds()
+		s.Metrics.DownloadTime = health.Timings.Download.Milliseconds()
+		return storeErr
+	}
+}
+
+// applyCert copies the served certificate into m and degrades an otherwise
+// healthy result when the certificate expires within the warning window.
+func applyCert(m *domain.Metrics, health *HealthResult, check *domain.HealthCheck, now time.Time) {
+	info := health.TLS
+	if info == nil {
+		return
+	}
+	m.CertExpiresAt = info.ExpiresAt
+	m.CertIssuer = info.Issuer
+	m.CertError = info.Error
+
+	warnDays := domain.DefaultCertWarnDays
+	if check != nil && check.CertWarnDays > 0 {
+		warnDays = check.CertWarnDays
+	}
+	m.CertExpiring = info.ExpiresAt.Before(now.AddDate(0, 0, warnDays))
+	if m.CertExpiring && health.Status == "healthy" {
+		health.Status = "degraded"
+		health.Reason = "certificate expires " + info.ExpiresAt.UTC().Format("2006-01-02")
+	}
+}
+
+func healthDownAfter(check *domain.HealthCheck) int {
+	if check == nil {
+		return 1
+	}
+	return max(check.DownAfter, 1)
 }
@@ -0,0 +1,499 @@
+package providers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/store"
+)
+
+func TestProbeHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		check      domain.HealthCheck
+		wantStatus string
+		wantReason string
+	}{
+		{name: "2xx is healthy by default", status: 200, body: "ok", wantStatus: "healthy"},
+		{name: "4xx is degraded by default", status: 404, wantStatus: "degraded", wantReason: "status 404"},
+		{name: "5xx is down by default", status: 503, wantStatus: "down", wantReason: "status 503"},
+		{
+			name:       "unexpected status is down",
+			status:     301,
+			check:      domain.HealthCheck{ExpectStatus: []int{200, 204}},
+			wantStatus: "down",
+			wantReason: "status 301, want 200/204",
+		},
+		{
+			name:       "expected non-2xx status is healthy",
+			status:     401,
+			check:      domain.HealthCheck{ExpectStatus: []int{401}},
+			wantStatus: "healthy",
+		},
+		{
+			name:       "200 error page fails body_contains",
+			status:     200,
+			body:       "<h1>Something went wrong</h1>",
+			check:      domain.HealthCheck{BodyContains: `"status":"ok"`},
+			wantStatus: "down",
+			wantReason: `body missing "\"status\":\"ok\""`,
+		},
+		{
+			name:       "body_matches passes",
+			status:     200,
+			body:       "build 1234 ready",
+			check:      domain.HealthCheck{BodyMatches: regexp.MustCompile(`build \d+ ready`)},
+			wantStatus: "healthy",
+		},
+		{
+			name:       "body_matches fails",
+			status:     200,
+			body:       "maintenance",
+			check:      domain.HealthCheck{BodyMatches: regexp.MustCompile(`^ready$`)},
+			wantStatus: "down",
+			wantReason: "body doesn't match /^ready$/",
+		},
+		{
+			name:   "json assertions pass",
+			status: 200,
+			body:   `{"status": "ok", "checks": {"db": true, "queue": 0}, "version": 3.0}`,
+			check: domain.HealthCheck{JSON: []domain.JSONAssert{
+				{Path: "$.status", Equals: "ok"},
+				{Path: "$.checks.db", Equals: true},
+				{Path: "$.checks.queue", Equals: 0},
+				{Path: "$.version", Equals: 3},
+			}},
+			wantStatus: "healthy",
+		},
+		{
+			name:       "json assertion mismatch is down",
+			status:     200,
+			body:       `{"checks": {"db": "down"}}`,
+			check:      domain.HealthCheck{JSON: []domain.JSONAssert{{Path: "$.checks.db", Equals: "ok"}}},
+			wantStatus: "down",
+			wantReason: `$.checks.db is "down", want "ok"`,
+		},
+		{
+			name:       "json assertion on non-JSON body is down",
+			status:     200,
+			body:       "<html>",
+			check:      domain.HealthCheck{JSON: []domain.JSONAssert{{Path: "$.status", Equals: "ok"}}},
+			wantStatus: "down",
+			wantReason: "body is not JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			got, err := probeHealth(context.Background(), srv.Client(), srv.URL, &tt.check)
+			if err != nil {
+				t.Fatalf("probeHealth() error = %v", err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %q (%s), want %q", got.Status, got.Reason, tt.wantStatus)
+			}
+			if tt.wantReason == "" && got.Reason != "" || !strings.HasPrefix(got.Reason, tt.wantReason) {
+				t.Errorf("Reason = %q, want %q", got.Reason, tt.wantReason)
+			}
+			if got.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", got.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestProbeHealthRequest(t *testing.T) {
+	var method, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, auth = r.Method, r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	check := &domain.HealthCheck{Method: http.MethodHead, Headers: map[string]string{"Authorization": "Bearer tok"}}
+	got, err := probeHealth(context.Background(), srv.Client(), srv.URL+"/api/health", check)
+	if err != nil {
+		t.Fatalf("probeHealth() error = %v", err)
+	}
+	if got.Status != "healthy" || method != http.MethodHead || auth != "Bearer tok" {
+		t.Errorf("status %q, request %s with auth %q; want healthy HEAD with bearer", got.Status, method, auth)
+	}
+
+	srv.Close()
+	got, err = probeHealth(context.Background(), srv.Client(), srv.URL, &domain.HealthCheck{})
+	if err != nil {
+		t.Fatalf("probeHealth() error = %v", err)
+	}
+	if got.Status != "down" || got.Reason == "" || strings.Contains(got.Reason, srv.URL) {
+		t.Errorf("closed server = %q (%q), want down with a reason not repeating the URL", got.Status, got.Reason)
+	}
+}
+
+func TestRunHealthCheck(t *testing.T) {
+	// serve answers each request with the next status in statuses, repeating the last.
+	serve := func(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+		t.Helper()
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := int(requests.Add(1))
+			w.WriteHeader(statuses[min(n, len(statuses))-1])
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &requests
+	}
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "retries a probe that finds the site down",
+			fn: func(t *testing.T) {
+				srv, requests := serve(t, 503, 502, 200)
+				got, err := runHealthCheck(context.Background(), srv.Client(), srv.URL, &domain.HealthCheck{Retries: 2}, 0)
+				if err != nil {
+					t.Fatalf("runHealthCheck() error = %v", err)
+				}
+				if got.Status != "healthy" || requests.Load() != 3 {
+					t.Errorf("status %q after %d requests, want healthy after 3", got.Status, requests.Load())
+				}
+			},
+		},
+		{
+			name: "gives up after the configured retries",
+			fn: func(t *testing.T) {
+				srv, requests := serve(t, 503)
+				got, err := runHealthCheck(context.Background(), srv.Client(), srv.URL, &domain.HealthCheck{Retries: 1}, 0)
+				if err != nil {
+					t.Fatalf("runHealthCheck() error = %v", err)
+				}
+				if got.Status != "down" || got.Reason != "status 503" || requests.Load() != 2 {
+					t.Errorf("status %q (%s) after %d requests, want down after 2", got.Status, got.Reason, requests.Load())
+				}
+			},
+		},
+		{
+			name: "does not retry a degraded probe",
+			fn: func(t *testing.T) {
+				srv, requests := serve(t, 404, 200)
+				got, err := runHealthCheck(context.Background(), srv.Client(), srv.URL, &domain.HealthCheck{Retries: 3}, 0)
+				if err != nil {
+					t.Fatalf("runHealthCheck() error = %v", err)
+				}
+				if got.Status != "degraded" || requests.Load() != 1 {
+					t.Errorf("status %q after %d requests, want degraded after 1", got.Status, requests.Load())
+				}
+			},
+		},
+		{
+			name: "one failed probe of three is degraded",
+			fn: func(t *testing.T) {
+				srv, requests := serve(t, 200, 503, 200)
+				got, err := runHealthCheck(context.Background(), srv.Client(), srv.URL, &domain.HealthCheck{Probes: 3}, 0)
+				if err != nil {
+					t.Fatalf("runHealthCheck() error = %v", err)
+				}
+				if got.Status != "degraded" || got.Reason != "1 of 3 probes failed: status 503" || requests.Load() != 3 {
+					t.Errorf("status %q (%s) after %d requests, want degraded after 3", got.Status, got.Reason, requests.Load())
+				}
+			},
+		},
+		{
+			name: "a majority of failed probes is down",
+			fn: func(t *testing.T) {
+				srv, _ := serve(t, 503, 200, 503)
+				got, err := runHealthCheck(context.Background(), srv.Client(), srv.URL, &domain.HealthCheck{Probes: 3}, 0)
+				if err != nil {
+					t.Fatalf("runHealthCheck() error = %v", err)
+				}
+				if got.Status != "down" || got.Reason != "2 of 3 probes failed: status 503" {
+					t.Errorf("status %q (%s), want down", got.Status, got.Reason)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
+
+func TestSummarizeProbes(t *testing.T) {
+	probes := func(latencies ...int64) []*HealthResult {
+		results := make([]*HealthResult, len(latencies))
+		for i, ms := range latencies {
+			results[i] = &HealthResult{Status: "healthy", ResponseTime: ms, StatusCode: 200}
+		}
+		return results
+	}
+
+	if got := summarizeProbes(probes(900, 100, 120), 0); got.ResponseTime != 120 || got.Status != "healthy" {
+		t.Errorf("odd probes = %dms %s, want median 120ms healthy", got.ResponseTime, got.Status)
+	}
+	if got := summarizeProbes(probes(100, 300, 200, 5000), 0); got.ResponseTime != 250 {
+		t.Errorf("even probes = %dms, want median 250ms", got.ResponseTime)
+	}
+	if got := summarizeProbes(probes(100, 3000, 120), time.Second); got.Status != "healthy" {
+		t.Errorf("one slow probe = %s (%s), want healthy: only the median counts", got.Status, got.Reason)
+	}
+	if got := summarizeProbes(probes(1500, 3000, 120), time.Second); got.Status != "degraded" || got.Reason != "slow: 1500ms over 1s" {
+		t.Errorf("slow median = %s (%s), want degraded", got.Status, got.Reason)
+	}
+
+	timed := probes(900, 100, 120)
+	for _, r := range timed {
+		r.Timings.TTFB = time.Duration(r.ResponseTime) * time.Millisecond
+	}
+	if got := summarizeProbes(timed, 0); got.Timings.TTFB != 120*time.Millisecond {
+		t.Errorf("Timings.TTFB = %s, want the median probe's 120ms", got.Timings.TTFB)
+	}
+}
+
+func TestHealthCheckerDownAfter(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+
+	// Nothing listens on port 1, so every check fails straight away.
+	product := domain.Product{Name: "App", Domain: "127.0.0.1:1", Health: &domain.HealthCheck{DownAfter: 3}}
+	checker := NewHealthChecker(s)
+	check := func() *domain.Metrics {
+		t.Helper()
+		sample := &Sample{Metrics: &domain.Metrics{ProductName: product.Name}}
+		collect := checker.Begin(context.Background(), []domain.Product{product}, Window{Now: time.Now()})
+		if err := collect(context.Background(), product, sample); err != nil {
+			t.Fatalf("collect() error = %v", err)
+		}
+		return sample.Metrics
+	}
+
+	for i := 1; i <= 2; i++ {
+		if m := check(); m.HealthStatus != "degraded" || !strings.HasPrefix(m.HealthReason, fmt.Sprintf("failed %d of 3 checks before down: ", i)) {
+			t.Fatalf("check %d = %s (%s), want degraded", i, m.HealthStatus, m.HealthReason)
+		}
+	}
+	if m := check(); m.HealthStatus != "down" {
+		t.Fatalf("check 3 = %s (%s), want down", m.HealthStatus, m.HealthReason)
+	}
+
+	product.Health = nil
+	checker = NewHealthChecker(nil)
+	if m := check(); m.HealthStatus != "down" {
+		t.Fatalf("storeless check = %s, want down on the first failure", m.HealthStatus)
+	}
+}
+
+// newCertServer starts an HTTPS server whose certificate, issued by a fresh
+// test CA, names hosts and expires at notAfter. The returned client trusts
+// that CA.
+func newCertServer(t *testing.T, hosts []string, notAfter time.Time) (*httptest.Server, *http.Client) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Overmind Test CA"},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("parse CA: %v", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate leaf key: %v", err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		NotBefore:    time.Now().Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			leafTemplate.IPAddresses = append(leafTemplate.IPAddresses, ip)
+		} else {
+			leafTemplate.DNSNames = append(leafTemplate.DNSNames, host)
+		}
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create leaf: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{leafDER}, PrivateKey: leafKey}}}
+	// Rejected handshakes are the point of most cases; don't log them.
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	t.Cleanup(client.CloseIdleConnections)
+	return srv, client
+}
+
+func TestProbeHealthTLS(t *testing.T) {
+	expires := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name       string
+		hosts      []string
+		notAfter   time.Time
+		untrusted  bool
+		wantStatus string
+		wantError  string
+	}{
+		{name: "valid certificate", hosts: []string{"127.0.0.1"}, notAfter: expires, wantStatus: "healthy"},
+		{name: "hostname mismatch", hosts: []string{"other.example"}, notAfter: expires, wantStatus: "down", wantError: "hostname mismatch: "},
+		{name: "expired", hosts: []string{"127.0.0.1"}, notAfter: time.Now().Add(-time.Hour), wantStatus: "down", wantError: "expired "},
+		{name: "untrusted issuer", hosts: []string{"127.0.0.1"}, notAfter: expires, untrusted: true, wantStatus: "down", wantError: "untrusted: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newCertServer(t, tt.hosts, tt.notAfter)
+			if tt.untrusted {
+				client = &http.Client{}
+			}
+
+			got, err := probeHealth(context.Background(), client, srv.URL, &domain.HealthCheck{})
+			if err != nil {
+				t.Fatalf("probeHealth() error = %v", err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %q (%s), want %q", got.Status, got.Reason, tt.wantStatus)
+			}
+			if got.TLS == nil {
+				t.Fatalf("TLS = nil, want certificate details")
+			}
+			if !got.TLS.ExpiresAt.Equal(tt.notAfter.Truncate(time.Second)) || got.TLS.Issuer != "Overmind Test CA" {
+				t.Errorf("TLS = %+v, want expiry %s from the test CA", got.TLS, tt.notAfter)
+			}
+			if !strings.HasPrefix(got.TLS.Error, tt.wantError) || (tt.wantError == "") != (got.TLS.Error == "") {
+				t.Errorf("TLS.Error = %q, want prefix %q", got.TLS.Error, tt.wantError)
+			}
+			if tt.wantError != "" && got.Reason != "certificate "+got.TLS.Error {
+				t.Errorf("Reason = %q, want the certificate error", got.Reason)
+			}
+		})
+	}
+}
+
+func TestProbeHealthTimings(t *testing.T) {
+	const delay = 30 * time.Millisecond
+	srv, client := newCertServer(t, []string{"127.0.0.1"}, time.Now().Add(24*time.Hour))
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		_, _ = w.Write([]byte("first"))
+		w.(http.Flusher).Flush()
+		time.Sleep(delay)
+		_, _ = w.Write([]byte("rest"))
+	})
+
+	got, err := probeHealth(context.Background(), client, srv.URL, &domain.HealthCheck{})
+	if err != nil {
+		t.Fatalf("probeHealth() error = %v", err)
+	}
+	timings := got.Timings
+	if timings.DNS != 0 {
+		t.Errorf("DNS = %s, want 0 for an IP address", timings.DNS)
+	}
+	if timings.Connect <= 0 || timings.TLS <= 0 {
+		t.Errorf("Connect = %s, TLS = %s, want both timed", timings.Connect, timings.TLS)
+	}
+	if timings.TTFB < delay || timings.Download < delay {
+		t.Errorf("TTFB = %s, Download = %s, want each at least %s", timings.TTFB, timings.Download, delay)
+	}
+	sum := timings.DNS + timings.Connect + timings.TLS + timings.TTFB + timings.Download
+	if total := time.Duration(got.ResponseTime) * time.Millisecond; sum > total+time.Millisecond {
+		t.Errorf("phases add up to %s, more than ResponseTime %s", sum, total)
+	}
+
+	srv.Close()
+	got, err = probeHealth(context.Background(), client, srv.URL, &domain.HealthCheck{})
+	if err != nil {
+		t.Fatalf("probeHealth() error = %v", err)
+	}
+	if got.Status != "down" || got.Timings.TTFB != 0 || got.Timings.Download != 0 {
+		t.Errorf("closed server = %s with %+v, want down without response phases", got.Status, got.Timings)
+	}
+}
+
+func TestApplyCert(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		expires      time.Time
+		check        *domain.HealthCheck
+		status       string
+		wantExpiring bool
+		wantStatus   string
+	}{
+		{name: "outside default window", expires: now.AddDate(0, 0, 30), status: "healthy", wantStatus: "healthy"},
+		{name: "inside default window", expires: now.AddDate(0, 0, 10), status: "healthy", wantExpiring: true, wantStatus: "degraded"},
+		{name: "configured window", expires: now.AddDate(0, 0, 30), check: &domain.HealthCheck{CertWarnDays: 45}, status: "healthy", wantExpiring: true, wantStatus: "degraded"},
+		{name: "down stays down", expires: now.AddDate(0, 0, 3), status: "down", wantExpiring: true, wantStatus: "down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &domain.Metrics{Timestamp: now}
+			health := &HealthResult{Status: tt.status, TLS: &TLSInfo{ExpiresAt: tt.expires, Issuer: "R11"}}
+			applyCert(m, health, tt.check, now)
+			if m.CertExpiring != tt.wantExpiring || health.Status != tt.wantStatus {
+				t.Errorf("expiring %v status %q, want %v %q", m.CertExpiring, health.Status, tt.wantExpiring, tt.wantStatus)
+			}
+			if !m.CertExpiresAt.Equal(tt.expires) || m.CertIssuer != "R11" {
+				t.Errorf("metrics = %v %q, want certificate copied", m.CertExpiresAt, m.CertIssuer)
+			}
+			if tt.wantStatus == "degraded" && health.Reason != "certificate expires "+tt.expires.Format("2006-01-02") {
+				t.Errorf("Reason = %q, want expiry date", health.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// HTTPJSONClient reads numbers from each product's own JSON endpoint, as
+// configured in the product's `providers.http` block. Each metric is reported
+// as "http.<name>" in Metrics.Values and kept as a daily series in the store.
+type HTTPJSONClient struct {
+	client *http.Client
+}
+
+// httpEndpoint is a JSON document served by the product itself, such as
+// /api/stats, and the numbers to read from it.
+type httpEndpoint struct {
+	URL         string            `json:"url"` // e.g. "https://chrondle.app/api/stats"
+	Headers     map[string]string `json:"headers"`
+	BearerToken string            `json:"bearer_token"`
+	Username    string            `json:"username"` // basic auth, with Password
+	Password    string            `json:"password"`
+	Metrics     []httpMetric      `json:"metrics"`
+}
+
+// httpMetric is one number extracted from an httpEndpoint response.
+type httpMetric struct {
+	Name   string `json:"name"`   // stored as "http.<name>"
+	Path   string `json:"path"`   // JSONPath-style, e.g. "$.data.signups" or "queues[0].depth"
+	Title  string `json:"title"`  // column header; defaults to the upper-cased name
+	Format string `json:"format"` // number (default), currency, percent or ms
+}
+
+// httpEndpointOf reads p's `providers.http` block. Unknown keys are rejected
+// so a misspelled setting fails validation instead of being ignored.
+func httpEndpointOf(p domain.Product) (*httpEndpoint, error) {
+	data, err := json.Marshal(p.Providers[ProviderHTTP])
+	if err != nil {
+		return nil, fmt.Errorf("http: encode config: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var endpoint httpEndpoint
+	if err := decoder.Decode(&endpoint); err != nil {
+		return nil, fmt.Errorf("http: config: %w", err)
+	}
+	for i, m := range endpoint.Metrics {
+		if m.Title == "" {
+			endpoint.Metrics[i].Title = strings.ToUpper(m.Name)
+		}
+	}
+	return &endpoint, nil
+}
+
+func NewHTTPJSONClient() *HTTPJSONClient {
+	return &HTTPJSONClient{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// httpJSONMaxBody caps how much of a stats response is read.
+const httpJSONMaxBody = 1 << 20
+
+// getJSON fetches endpoint and decodes its body, keeping numbers exact.
+func (c *HTTPJSONClient) getJSON(ctx context.Context, endpoint *httpEndpoint) (any, error) {
+	if endpoint == nil || endpoint.URL == "" {
+		return nil, fmt.Errorf("http: url is empty")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("http: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range endpoint.Headers {
+		req.Header.Set(key, value)
+	}
+	switch {
+	case endpoint.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+endpoint.BearerToken)
+	case endpoint.Username != "" || endpoint.Password != "":
+		req.SetBasicAuth(endpoint.Username, endpoint.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http: get: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, httpJSONMaxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("http: read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("http: get failed: status %d: %s", resp.StatusCode, truncate(string(body), 200))
+	}
+	if len(body) > httpJSONMaxBody {
+		return nil, fmt.Errorf("http: response exceeds %d bytes", httpJSONMaxBody)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("http: decode response: %w", err)
+	}
+	return doc, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
+
+func (c *HTTPJSONClient) Name() string  { return ProviderHTTP }
+func (c *HTTPJSONClient) Label() string { return "HTTP" }
+
+func (c *HTTPJSONClient) Enabled(p domain.Product) bool {
+	_, ok := p.Providers[ProviderHTTP]
+	return ok
+}
+
+// Columns returns one column per metric name, in the order products first
+// declare them. The first declaration's title and format win.
+func (c *HTTPJSONClient) Columns(products []domain.Product) []Column {
+	var columns []Column
+	seen := make(map[string]bool)
+	for _, p := range products {
+		if !c.Enabled(p) {
+			continue
+		}
+		// ValidateProduct has already rejected configs that don't parse.
+		endpoint, err := httpEndpointOf(p)
+		if err != nil {
+			continue
+		}
+		for _, m := range endpoint.Metrics {
+			key := ProviderHTTP + "." + m.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			columns = append(columns, Column{Key: key, Title: m.Title, Format: m.Format})
+		}
+	}
+	return columns
+}
+
+// ValidateProduct checks the endpoint URL and every metric's name, path and
+// format.
+func (c *HTTPJSONClient) ValidateProduct(p domain.Product) error {
+	endpoint, err := httpEndpointOf(p)
+	if err != nil {
+		return err
+	}
+	if u, err := url.Parse(endpoint.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an absolute http(s) URL", endpoint.URL)
+	}
+	if len(endpoint.Metrics) == 0 {
+		return fmt.Errorf("no metrics defined")
+	}
+	seen := make(map[string]bool, len(endpoint.Metrics))
+	for i, m := range endpoint.Metrics {
+		if m.Name == "" || m.Path == "" {
+			return fmt.Errorf("metrics[%d] needs name and path", i)
+		}
+		if strings.Contains(m.Name, ".") {
+			return fmt.Errorf("metric name %q must not contain '.'", m.Name)
+		}
+		if seen[m.Name] {
+			return fmt.Errorf("duplicate metric %q", m.Name)
+		}
+		seen[m.Name] = true
+		if _, err := parseJSONPath(m.Path); err != nil {
+			return fmt.Errorf("metric %q: %w", m.Name, err)
+		}
+		if !validFormat(m.Format) || m.Format == FormatText {
+			return fmt.Errorf("metric %q: unsupported format %q", m.Name, m.Format)
+		}
+	}
+	return nil
+}
+
+// Begin returns a collector that makes one request per product. A metric
+// whose path is missing or not numeric is reported without losing the rest.
+func (c *HTTPJSONClient) Begin(_ context.Context, _ []domain.Product, w Window) CollectFunc {
+	today := domain.StartOfDayUTC(w.Now)
+	return func(ctx context.Context, p domain.Product, s *Sample) error {
+		endpoint, err := httpEndpointOf(p)
+		if err != nil {
+			return err
+		}
+		doc, err := c.getJSON(ctx, endpoint)
+		if err != nil {
+			return err
+		}
+
+		var errs []string
+		for _, m := range endpoint.Metrics {
+			value, err := extractJSONNumber(doc, m.Path)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", m.Name, err))
+				continue
+			}
+			key := ProviderHTTP + "." + m.Name
+			s.Metrics.SetValue(key, value)
+			// The daily series holds whole numbers; the snapshot keeps the
+			// exact value. Rounding a percentage or ratio would leave
+			// nothing worth charting, so those get no daily series.
+			if m.Format != FormatPercent {
+				s.Daily = append(s.Daily, domain.DailyValue{Day: today, Metric: key, Value: int64(math.Round(value))})
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("http: %s", strings.Join(errs, "; "))
+		}
+		return nil
+	}
+}
+
+// jsonPathStep is one object key or array index in a parsed path.
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath parses the JSONPath subset used for stats endpoints: an
+// optional leading "$", dotted keys, [n] array indexes and quoted keys for
+// names containing dots or brackets, e.g. `$.data.queues[0]["p.95"]`.
+func parseJSONPath(expr string) ([]jsonPathStep, error) {
+	rest := strings.TrimSpace(expr)
+	rest = strings.TrimPrefix(rest, "$")
+	if rest == "" {
+		return nil, fmt.Errorf("path %q selects nothing", expr)
+	}
+
+	var steps []jsonPathStep
+	first := true
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, `["`) || strings.HasPrefix(rest, `['`):
+			// Quoted keys may contain dots and brackets, so look for the
+			// closing quote rather than the first ']'.
+			quote := rest[1]
+			end := strings.IndexByte(rest[2:], quote) + 2
+			if end < 2 || end+1 >= len(rest) || rest[end+1] != ']' {
+				return nil, fmt.Errorf("path %q: unclosed quoted key", expr)
+			}
+			steps = append(steps, jsonPathStep{key: rest[2:end]})
+			rest = rest[end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: unclosed [", expr)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("path %q: bad index %s", expr, rest[:end+1])
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			rest = rest[end+1:]
+		case rest[0] == '.' || first:
+			if rest[0] == '.' {
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("path %q: empty key", expr)
+			}
+			steps = append(steps, jsonPathStep{key: rest[:end]})
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("path %q: unexpected %q", expr, rest[0])
+		}
+		first = false
+	}
+	return steps, nil
+}
+
+// lookupJSONPath follows path through doc and returns the value there.
+func lookupJSONPath(doc any, path string) (any, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for _, step := range steps {
+		if step.isIndex {
+			list, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("path %q: [%d] on a non-array", path, step.index)
+			}
+			if step.index >= len(list) {
+				return nil, fmt.Errorf("path %q: index %d out of range (len %d)", path, step.index, len(list))
+			}
+			current = list[step.index]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("path %q: key %q on a non-object", path, step.key)
+		}
+		if current, ok = object[step.key]; !ok {
+			return nil, fmt.Errorf("path %q: key %q not found", path, step.key)
+		}
+	}
+	return current, nil
+}
+
+// extractJSONNumber returns the number at path in doc. Numeric strings and
+// booleans (as 1 or 0) are accepted.
+func extractJSONNumber(doc any, path string) (float64, error) {
+	current, err := lookupJSONPath(doc, path)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := current.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("path %q: %q is not a number", path, v)
+		}
+		return f, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, fmt.Errorf("path %q is null", path)
+	default:
+		return 0, fmt.Errorf("path %q: %s is not a number", path, describeJSON(v))
+	}
+}
+
+func describeJSON(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	return fmt.Sprintf("%T", v)
+}
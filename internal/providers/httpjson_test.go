@@ -0,0 +1,280 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+func TestExtractJSONNumber(t *testing.T) {
+	var doc any
+	raw := `{
+		"signups": 42,
+		"data": {"mrr": "1999.5", "active": true, "queues": [{"depth": 3}, {"depth": 9}]},
+		"latency": {"p.95": 120, "a]b": 7},
+		"empty": null,
+		"big": 12345678901234567
+	}`
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    float64
+		wantErr string
+	}{
+		{name: "root key", path: "$.signups", want: 42},
+		{name: "without dollar", path: "signups", want: 42},
+		{name: "nested numeric string", path: "$.data.mrr", want: 1999.5},
+		{name: "boolean", path: "data.active", want: 1},
+		{name: "array index", path: "$.data.queues[1].depth", want: 9},
+		{name: "quoted key with dot", path: `$.latency["p.95"]`, want: 120},
+		{name: "single-quoted key with bracket", path: `$.latency['a]b']`, want: 7},
+		{name: "large integer", path: "$.big", want: 12345678901234567},
+		{name: "missing key", path: "$.data.churn", wantErr: `key "churn" not found`},
+		{name: "index out of range", path: "$.data.queues[2].depth", wantErr: "out of range"},
+		{name: "index on object", path: "$.data[0]", wantErr: "non-array"},
+		{name: "not a number", path: "$.data.queues", wantErr: "array is not a number"},
+		{name: "null", path: "$.empty", wantErr: "is null"},
+		{name: "empty path", path: "$", wantErr: "selects nothing"},
+		{name: "empty key", path: "$.data..mrr", wantErr: "empty key"},
+		{name: "bad index", path: "$.data.queues[x]", wantErr: "bad index"},
+		{name: "unclosed quote", path: `$.latency["p.95]`, wantErr: "unclosed quoted key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractJSONNumber(doc, tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("extractJSONNumber(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractJSONNumber(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("extractJSONNumber(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPJSONClient(t *testing.T) {
+	endpoint := func(url string) *httpEndpoint {
+		return &httpEndpoint{
+			URL: url,
+			Metrics: []httpMetric{
+				{Name: "signups", Path: "$.signups", Title: "SIGNUPS"},
+				{Name: "mrr", Path: "$.revenue.mrr_cents", Title: "MRR", Format: FormatCurrency},
+			},
+		}
+	}
+	collect := func(t *testing.T, p domain.Product, now time.Time) (*Sample, error) {
+		t.Helper()
+		s := &Sample{Metrics: &domain.Metrics{ProductName: p.Name}}
+		c := NewHTTPJSONClient().Begin(context.Background(), []domain.Product{p}, Window{Now: now})
+		return s, c(context.Background(), p, s)
+	}
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "sends headers and auth and records values and daily history",
+			fn: func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("X-Team") != "core" {
+						http.Error(w, "unauthorized", http.StatusUnauthorized)
+						return
+					}
+					_, _ = w.Write([]byte(`{"signups": 12, "revenue": {"mrr_cents": 4999.6}}`))
+				}))
+				defer srv.Close()
+
+				e := endpoint(srv.URL)
+				e.BearerToken = "tok"
+				e.Headers = map[string]string{"X-Team": "core"}
+				now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC)
+				s, err := collect(t, httpProduct(t, "App", e), now)
+				if err != nil {
+					t.Fatalf("collect() error = %v", err)
+				}
+
+				want := map[string]float64{"http.signups": 12, "http.mrr": 4999.6}
+				if !reflect.DeepEqual(s.Metrics.Values, want) {
+					t.Errorf("Values = %v, want %v", s.Metrics.Values, want)
+				}
+				day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+				wantDaily := []domain.DailyValue{
+					{Day: day, Metric: "http.signups", Value: 12},
+					{Day: day, Metric: "http.mrr", Value: 5000},
+				}
+				if !reflect.DeepEqual(s.Daily, wantDaily) {
+					t.Errorf("Daily = %v, want %v", s.Daily, wantDaily)
+				}
+			},
+		},
+		{
+			name: "keeps percent metrics out of the daily series",
+			fn: func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(`{"signups": 12, "revenue": {"mrr_cents": 100}, "conversion": 0.42}`))
+				}))
+				defer srv.Close()
+
+				e := endpoint(srv.URL)
+				e.Metrics = append(e.Metrics, httpMetric{Name: "conversion", Path: "$.conversion", Title: "CONV", Format: FormatPercent})
+				s, err := collect(t, httpProduct(t, "App", e), time.Now())
+				if err != nil {
+					t.Fatalf("collect() error = %v", err)
+				}
+				if s.Metrics.Values["http.conversion"] != 0.42 {
+					t.Errorf("Values = %v, want exact conversion", s.Metrics.Values)
+				}
+				for _, v := range s.Daily {
+					if v.Metric == "http.conversion" {
+						t.Errorf("Daily = %v, want no conversion series", s.Daily)
+					}
+				}
+			},
+		},
+		{
+			name: "uses basic auth",
+			fn: func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if user, pass, ok := r.BasicAuth(); !ok || user != "ops" || pass != "secret" {
+						http.Error(w, "unauthorized", http.StatusUnauthorized)
+						return
+					}
+					_, _ = w.Write([]byte(`{"signups": 1, "revenue": {"mrr_cents": 0}}`))
+				}))
+				defer srv.Close()
+
+				e := endpoint(srv.URL)
+				e.Username, e.Password = "ops", "secret"
+				if _, err := collect(t, httpProduct(t, "App", e), time.Now()); err != nil {
+					t.Fatalf("collect() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "keeps metrics that resolve when others fail",
+			fn: func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(`{"signups": 5}`))
+				}))
+				defer srv.Close()
+
+				s, err := collect(t, httpProduct(t, "App", endpoint(srv.URL)), time.Now())
+				if err == nil || !strings.Contains(err.Error(), `mrr: path "$.revenue.mrr_cents"`) {
+					t.Fatalf("collect() error = %v, want mrr path error", err)
+				}
+				if s.Metrics.Values["http.signups"] != 5 {
+					t.Errorf("Values = %v, want signups kept", s.Metrics.Values)
+				}
+			},
+		},
+		{
+			name: "reports non-2xx status",
+			fn: func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, "nope", http.StatusForbidden)
+				}))
+				defer srv.Close()
+
+				_, err := collect(t, httpProduct(t, "App", endpoint(srv.URL)), time.Now())
+				if err == nil || !strings.Contains(err.Error(), "status 403") {
+					t.Fatalf("collect() error = %v, want status 403", err)
+				}
+			},
+		},
+		{
+			name: "columns follow first declaration across products",
+			fn: func(t *testing.T) {
+				products := []domain.Product{
+					httpProduct(t, "A", endpoint("https://a.com/api/stats")),
+					{Name: "B"},
+					httpProduct(t, "C", &httpEndpoint{Metrics: []httpMetric{
+						{Name: "mrr", Title: "REVENUE"},
+						{Name: "queue"},
+					}}),
+				}
+				want := []Column{
+					{Key: "http.signups", Title: "SIGNUPS"},
+					{Key: "http.mrr", Title: "MRR", Format: FormatCurrency},
+					{Key: "http.queue", Title: "QUEUE"},
+				}
+				if got := NewHTTPJSONClient().Columns(products); !reflect.DeepEqual(got, want) {
+					t.Errorf("Columns() = %+v, want %+v", got, want)
+				}
+			},
+		},
+		{
+			name: "registry validation rejects bad settings",
+			fn: func(t *testing.T) {
+				registry := newTestRegistry(t, NewHTTPJSONClient())
+				validate := func(e *httpEndpoint) error {
+					return registry.Validate([]domain.Product{httpProduct(t, "App", e)})
+				}
+				signups := httpMetric{Name: "signups", Path: "$.signups"}
+
+				if err := validate(&httpEndpoint{URL: "https://app.com/api/stats", Metrics: []httpMetric{signups}}); err != nil {
+					t.Fatalf("Validate() error = %v, want valid", err)
+				}
+				for _, tc := range []struct {
+					endpoint *httpEndpoint
+					wantErr  string
+				}{
+					{&httpEndpoint{URL: "app.com/api/stats", Metrics: []httpMetric{signups}}, `product "App": http: url "app.com/api/stats" must be an absolute http(s) URL`},
+					{&httpEndpoint{URL: "https://app.com/api/stats"}, "no metrics defined"},
+					{&httpEndpoint{URL: "https://app.com/api/stats", Metrics: []httpMetric{{Name: "signups"}}}, "metrics[0] needs name and path"},
+					{&httpEndpoint{URL: "https://app.com/api/stats", Metrics: []httpMetric{signups, signups}}, `duplicate metric "signups"`},
+					{&httpEndpoint{URL: "https://app.com/api/stats", Metrics: []httpMetric{{Name: "signups", Path: "$.signups[x]"}}}, `metric "signups"`},
+					{&httpEndpoint{URL: "https://app.com/api/stats", Metrics: []httpMetric{{Name: "plan", Path: "$.plan", Format: FormatText}}}, "unsupported format"},
+				} {
+					if err := validate(tc.endpoint); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+						t.Errorf("Validate(%+v) error = %v, want %q", tc.endpoint, err, tc.wantErr)
+					}
+				}
+
+				product := domain.Product{Name: "App", Providers: map[string]domain.ProviderConfig{ProviderHTTP: {"url": "https://app.com/api/stats", "metric": []any{}}}}
+				if err := registry.Validate([]domain.Product{product}); err == nil || !strings.Contains(err.Error(), `unknown field "metric"`) {
+					t.Errorf("Validate() error = %v, want unknown field", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
+
+// httpProduct returns a product whose providers.http block holds e, shaped as
+// the config file would decode it.
+func httpProduct(t *testing.T, name string, e *httpEndpoint) domain.Product {
+	t.Helper()
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("marshal endpoint: %v", err)
+	}
+	var cfg domain.ProviderConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("unmarshal endpoint: %v", err)
+	}
+	return domain.Product{Name: name, Providers: map[string]domain.ProviderConfig{ProviderHTTP: cfg}}
+}
@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
 )
 
 // escapeHogQLLike escapes special characters for ClickHouse LIKE clauses
@@ -60,27 +64,197 @@ type PostHogAnalytics struct {
 	Visitors  int64
 }
 
-// GetPageviews queries PostHog for pageview counts using HogQL
-func (c *PostHogClient) GetPageviews(ctx context.Context, hostFilter string, from, to time.Time) (*PostHogAnalytics, error) {
+// GetPageviewsByHost returns pageviews and visitors for every host filter
+// using one HogQL query grouped by the filters each event's host matches, so
+// overlapping filters both count it and a visitor seen on two hosts matching
+// one filter (e.g. "app.com" and "www.app.com") is counted once.
+func (c *PostHogClient) GetPageviewsByHost(ctx context.Context, hostFilters []string, from, to time.Time) (map[string]*PostHogAnalytics, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("posthog: api key is empty")
+	}
+
+	filters, conditions := hostConditions(hostFilters)
+	analytics := make(map[string]*PostHogAnalytics, len(filters))
+	for _, filter := range filters {
+		analytics[filter] = &PostHogAnalytics{}
+	}
+	if len(conditions) == 0 {
+		return analytics, nil
+	}
+
+	// HogQL query for pageviews and unique visitors per host filter
+	rows, err := c.query(ctx, fmt.Sprintf(`
+		SELECT
+			%s as filter,
+			count() as pageviews,
+			count(DISTINCT distinct_id) as visitors
+		FROM events
+		WHERE event = '$pageview'
+		AND (%s)
+		AND timestamp >= toDateTime('%s')
+		AND timestamp <= toDateTime('%s')
+		GROUP BY filter
+		LIMIT %d
+	`, matchedFilters(filters, conditions), strings.Join(conditions, " OR "), formatHogQLTime(from), formatHogQLTime(to), hogQLMaxRows))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) >= hogQLMaxRows {
+		return nil, fmt.Errorf("posthog: pageview query hit %d row limit", hogQLMaxRows)
+	}
+
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		filter, _ := row[0].(string)
+		a, ok := analytics[filter]
+		if !ok {
+			continue
+		}
+		pageviews, _ := row[1].(float64)
+		visitors, _ := row[2].(float64)
+		a.Pageviews += int64(pageviews)
+		a.Visitors += int64(visitors)
+	}
+
+	return analytics, nil
+}
+
+// GetDailyPageviewsByHost returns per-day pageviews and visitors for every host
+// filter between from and to, bucketed by UTC day with toStartOfDay. Events
+// are grouped by the filters they match as in GetPageviewsByHost, so each
+// day's visitors are distinct per filter. Days without traffic are omitted.
+func (c *PostHogClient) GetDailyPageviewsByHost(ctx context.Context, hostFilters []string, from, to time.Time) (map[string][]domain.DailyTraffic, error) {
 	if c.apiKey == "" {
 		return nil, fmt.Errorf("posthog: api key is empty")
 	}
 
-	// HogQL query for pageviews and unique visitors
-	// Escape hostFilter to prevent LIKE injection (wildcards: %, _, \)
-	safeHost := escapeHogQLLike(hostFilter)
+	filters, conditions := hostConditions(hostFilters)
+	daily := make(map[string][]domain.DailyTraffic, len(filters))
+	if len(conditions) == 0 {
+		return daily, nil
+	}
+
+	rows, err := c.query(ctx, fmt.Sprintf(`
+		SELECT
+			%s as filter,
+			toStartOfDay(toTimeZone(timestamp, 'UTC')) as day,
+			count() as pageviews,
+			count(DISTINCT distinct_id) as visitors
+		FROM events
+		WHERE event = '$pageview'
+		AND (%s)
+		AND timestamp >= toDateTime('%s')
+		AND timestamp <= toDateTime('%s')
+		GROUP BY filter, day
+		ORDER BY day
+		LIMIT %d
+	`, matchedFilters(filters, conditions), strings.Join(conditions, " OR "), formatHogQLTime(from), formatHogQLTime(to), hogQLMaxRows))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) >= hogQLMaxRows {
+		return nil, fmt.Errorf("posthog: daily query hit %d row limit; use a shorter window", hogQLMaxRows)
+	}
+
+	byDay := make(map[string]map[time.Time]*domain.DailyTraffic, len(filters))
+	for _, row := range rows {
+		if len(row) < 4 {
+			continue
+		}
+		filter, _ := row[0].(string)
+		dayValue, _ := row[1].(string)
+		day, err := parseHogQLDay(dayValue)
+		if err != nil {
+			return nil, err
+		}
+		pageviews, _ := row[2].(float64)
+		visitors, _ := row[3].(float64)
+		if byDay[filter] == nil {
+			byDay[filter] = make(map[time.Time]*domain.DailyTraffic)
+		}
+		bucket := byDay[filter][day]
+		if bucket == nil {
+			bucket = &domain.DailyTraffic{Day: day}
+			byDay[filter][day] = bucket
+		}
+		bucket.Visits += int64(pageviews)
+		bucket.Uniques += int64(visitors)
+	}
+
+	for _, filter := range filters {
+		days := make([]domain.DailyTraffic, 0, len(byDay[filter]))
+		for _, bucket := range byDay[filter] {
+			days = append(days, *bucket)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i].Day.Before(days[j].Day) })
+		daily[filter] = days
+	}
+	return daily, nil
+}
+
+// hostConditions de-duplicates host filters and builds an escaped LIKE
+// condition for each.
+func hostConditions(hostFilters []string) ([]string, []string) {
+	seen := make(map[string]struct{}, len(hostFilters))
+	var filters, conditions []string
+	for _, filter := range hostFilters {
+		if filter == "" {
+			continue
+		}
+		if _, ok := seen[filter]; ok {
+			continue
+		}
+		seen[filter] = struct{}{}
+		filters = append(filters, filter)
+		// Escape filter to prevent LIKE injection (wildcards: %, _, \)
+		conditions = append(conditions, fmt.Sprintf("properties.$host LIKE '%%%s%%'", escapeHogQLLike(filter)))
+	}
+	return filters, conditions
+}
+
+// matchedFilters returns a HogQL expression that expands each event into
+// one row per host filter its host matches, named by the filter, so
+// grouping by it counts distinct visitors per filter. conditions are
+// hostConditions' LIKE conditions for filters, in the same order.
+func matchedFilters(filters, conditions []string) string {
+	cases := make([]string, len(filters))
+	for i, filter := range filters {
+		cases[i] = fmt.Sprintf("if(%s, '%s', '')", conditions[i], escapeHogQLString(filter))
+	}
+	return fmt.Sprintf("arrayJoin(arrayFilter(f -> f != '', [%s]))", strings.Join(cases, ", "))
+}
+
+// escapeHogQLString escapes s for use inside a single-quoted HogQL string.
+func escapeHogQLString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `'`, `''`)
+}
+
+// parseHogQLDay parses a day bucket, which PostHog may return as an ISO
+// timestamp with or without a zone, or as a bare date.
+func parseHogQLDay(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("posthog: unexpected day value %q", value)
+}
+
+// hogQLMaxRows overrides HogQL's default 100-row result limit.
+const hogQLMaxRows = 10000
+
+func formatHogQLTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// query runs a HogQL query and returns its result rows.
+func (c *PostHogClient) query(ctx context.Context, hogql string) ([][]interface{}, error) {
 	query := map[string]interface{}{
-		"kind": "HogQLQuery",
-		"query": fmt.Sprintf(`
-			SELECT
-				count() as pageviews,
-				count(DISTINCT distinct_id) as visitors
-			FROM events
-			WHERE event = '$pageview'
-			AND properties.$host LIKE '%%%s%%'
-			AND timestamp >= toDateTime('%s')
-			AND timestamp <= toDateTime('%s')
-		`, safeHost, from.UTC().Format("2006-01-02 15:04:05"), to.UTC().Format("2006-01-02 15:04:05")),
+		"kind":  "HogQLQuery",
+		"query": hogql,
 	}
 
 	body, err := json.Marshal(map[string]interface{}{"query": query})
@@ -116,15 +290,44 @@ func (c *PostHogClient) GetPageviews(ctx context.Context, hostFilter string, fro
 		return nil, fmt.Errorf("posthog: decode response: %w", err)
 	}
 
-	analytics := &PostHogAnalytics{}
-	if len(result.Results) > 0 && len(result.Results[0]) >= 2 {
-		if v, ok := result.Results[0][0].(float64); ok {
-			analytics.Pageviews = int64(v)
-		}
-		if v, ok := result.Results[0][1].(float64); ok {
-			analytics.Visitors = int64(v)
+	return result.Results, nil
+}
+
+func (c *PostHogClient) Name() string  { return ProviderPostHog }
+func (c *PostHogClient) Label() string { return "PostHog" }
+
+func (c *PostHogClient) Enabled(p domain.Product) bool { return p.PostHogHost != "" }
+
+// Begin shares one grouped weekly query and one grouped daily query across
+// every product. Rolling weekly uniques can't be summed from daily buckets,
+// so the trend window needs its own query.
+func (c *PostHogClient) Begin(ctx context.Context, products []domain.Product, w Window) CollectFunc {
+	hostFilters := make([]string, 0, len(products))
+	for _, p := range products {
+		if p.PostHogHost != "" {
+			hostFilters = append(hostFilters, p.PostHogHost)
 		}
 	}
+	weekly := sync.OnceValues(func() (map[string]*PostHogAnalytics, error) {
+		return c.GetPageviewsByHost(ctx, hostFilters, w.WeekAgo, w.Now)
+	})
+	daily := sync.OnceValues(func() (map[string][]domain.DailyTraffic, error) {
+		return c.GetDailyPageviewsByHost(ctx, hostFilters, w.TrendStart, w.Now)
+	})
 
-	return analytics, nil
+	return func(_ context.Context, p domain.Product, s *Sample) error {
+		traffic, err := weekly()
+		if err != nil {
+			return err
+		}
+		if analytics := traffic[p.PostHogHost]; analytics != nil {
+			s.Metrics.Visits = analytics.Pageviews
+			s.Metrics.Uniques = analytics.Visitors
+		}
+		// The weekly query above already reports PostHog failures.
+		if days, err := daily(); err == nil {
+			s.Daily = append(s.Daily, dailyTrafficValues(days[p.PostHogHost], w.TrendStart, w.TrendDays)...)
+		}
+		return nil
+	}
 }
@@ -1,6 +1,17 @@
 package providers
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
 
 func TestEscapeHogQLLike(t *testing.T) {
 	tests := []struct {
@@ -112,3 +123,150 @@ func TestNewPostHogClient_HostValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPageviewsByHost(t *testing.T) {
+	var (
+		calls int
+		query string
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/api/projects/proj/query/" {
+			t.Errorf("path = %q, want query endpoint", r.URL.Path)
+		}
+		var body struct {
+			Query struct {
+				Query string `json:"query"`
+			} `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		query = body.Query.Query
+		_, _ = w.Write([]byte(`{"results": [
+			["app.com", 150, 90],
+			["my_site.io", 5, 4]
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewPostHogClient("key", "proj", server.URL)
+	client.client = server.Client()
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	got, err := client.GetPageviewsByHost(context.Background(), []string{"app.com", "my_site.io", "app.com", "quiet.dev"}, from, to)
+	if err != nil {
+		t.Fatalf("GetPageviewsByHost() error = %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("query calls = %d, want 1", calls)
+	}
+	for _, want := range []string{
+		`properties.$host LIKE '%app.com%' OR properties.$host LIKE '%my\_site.io%' OR properties.$host LIKE '%quiet.dev%'`,
+		`arrayJoin(arrayFilter(f -> f != '', [if(properties.$host LIKE '%app.com%', 'app.com', ''), if(properties.$host LIKE '%my\_site.io%', 'my_site.io', ''), if(properties.$host LIKE '%quiet.dev%', 'quiet.dev', '')])) as filter`,
+		"GROUP BY filter",
+		"toDateTime('2026-01-01 00:00:00')",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+
+	want := map[string]*PostHogAnalytics{
+		"app.com":    {Pageviews: 150, Visitors: 90},
+		"my_site.io": {Pageviews: 5, Visitors: 4},
+		"quiet.dev":  {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetPageviewsByHost() = %v, want %v", got, want)
+	}
+}
+
+func TestGetPageviewsByHostRowLimit(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rows := make([]string, hogQLMaxRows)
+		for i := range rows {
+			rows[i] = `["app.com", 1, 1]`
+		}
+		_, _ = w.Write([]byte(`{"results": [` + strings.Join(rows, ",") + `]}`))
+	}))
+	defer server.Close()
+
+	client := NewPostHogClient("key", "proj", server.URL)
+	client.client = server.Client()
+
+	_, err := client.GetPageviewsByHost(context.Background(), []string{"app.com"}, time.Now(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "row limit") {
+		t.Fatalf("GetPageviewsByHost() error = %v, want row limit error", err)
+	}
+}
+
+func TestGetPageviewsByHostNoFilters(t *testing.T) {
+	client := NewPostHogClient("key", "proj", "")
+	got, err := client.GetPageviewsByHost(context.Background(), []string{""}, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("GetPageviewsByHost() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("GetPageviewsByHost() = %v, want empty", got)
+	}
+}
+
+func TestGetDailyPageviewsByHost(t *testing.T) {
+	var query string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query struct {
+				Query string `json:"query"`
+			} `json:"query"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		query = body.Query.Query
+		_, _ = w.Write([]byte(`{"results": [
+			["app.com", "2026-01-01T00:00:00Z", 15, 8],
+			["app.com", "2026-01-03", 7, 4]
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewPostHogClient("key", "proj", server.URL)
+	client.client = server.Client()
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	got, err := client.GetDailyPageviewsByHost(context.Background(), []string{"app.com", "quiet.dev"}, from, from.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("GetDailyPageviewsByHost() error = %v", err)
+	}
+	if !strings.Contains(query, "toStartOfDay(") || !strings.Contains(query, "GROUP BY filter, day") {
+		t.Errorf("query missing daily grouping:\n%s", query)
+	}
+
+	want := map[string][]domain.DailyTraffic{
+		"app.com": {
+			{Day: from, Visits: 15, Uniques: 8},
+			{Day: from.AddDate(0, 0, 2), Visits: 7, Uniques: 4},
+		},
+		"quiet.dev": {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetDailyPageviewsByHost() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseHogQLDay(t *testing.T) {
+	want := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{"2026-03-04T00:00:00Z", "2026-03-04T00:00:00", "2026-03-04 00:00:00", "2026-03-04"} {
+		got, err := parseHogQLDay(value)
+		if err != nil {
+			t.Fatalf("parseHogQLDay(%q) error = %v", value, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseHogQLDay(%q) = %v, want %v", value, got, want)
+		}
+	}
+	if _, err := parseHogQLDay("yesterday"); err == nil {
+		t.Errorf("parseHogQLDay(yesterday) error = nil, want error")
+	}
+}
@@ -0,0 +1,153 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// Provider is a metrics source. The built-in clients implement it, and new
+// sources plug in by registering with a Registry; the fetcher, store and TUI
+// need no changes for a provider that reports through Metrics.Values/Labels.
+type Provider interface {
+	// Name is the registry key. It names the provider's block in a product's
+	// `providers` config and is reported in Update.Provider.
+	Name() string
+	// Label prefixes the provider's errors, e.g. "PostHog".
+	Label() string
+	// Enabled reports whether p has this provider configured.
+	Enabled(p domain.Product) bool
+	// Begin starts one refresh over products and returns the function that
+	// collects a single product's metrics. Account-wide queries shared by
+	// every product belong behind Begin, run lazily on first use.
+	Begin(ctx context.Context, products []domain.Product, w Window) CollectFunc
+}
+
+// CollectFunc gathers one product's metrics into s.
+type CollectFunc func(ctx context.Context, p domain.Product, s *Sample) error
+
+// Window is the time range shared by every product within one refresh.
+type Window struct {
+	Now        time.Time
+	WeekAgo    time.Time
+	TrendStart time.Time // midnight UTC, TrendDays-1 days before today
+	TrendDays  int
+}
+
+// Sample receives one product's results from a provider.
+type Sample struct {
+	Metrics *domain.Metrics
+	Daily   []domain.DailyValue // upserted into the store's daily series
+}
+
+// Column describes a Metrics.Values or Metrics.Labels entry for display.
+type Column struct {
+	Key      string // "provider.metric"
+	Title    string // header, e.g. "SIGNUPS"
+	Format   string // FormatNumber (default), FormatCurrency, FormatPercent, FormatMillis or FormatText
+	Width    int    // cells; 0 uses the title width
+	Provider string // set by MetricsFetcher.Columns
+}
+
+// Column formats.
+const (
+	FormatNumber   = "number"
+	FormatCurrency = "currency" // cents
+	FormatPercent  = "percent"
+	FormatMillis   = "ms"
+	FormatText     = "text" // read from Metrics.Labels
+)
+
+// ColumnProvider is implemented by providers that report generic metrics and
+// want them shown as dashboard columns. Columns may depend on how products
+// configure the provider.
+type ColumnProvider interface {
+	Columns(products []domain.Product) []Column
+}
+
+// ProductValidator is implemented by providers that can check a product's
+// settings up front, so mistakes surface at startup rather than as a fetch
+// error on every refresh.
+type ProductValidator interface {
+	ValidateProduct(p domain.Product) error
+}
+
+// validFormat reports whether format is a known Column format.
+func validFormat(format string) bool {
+	switch format {
+	case "", FormatNumber, FormatCurrency, FormatPercent, FormatMillis, FormatText:
+		return true
+	}
+	return false
+}
+
+// Registry holds providers in registration order. Each product's providers
+// run concurrently, so their results arrive in whatever order they finish.
+type Registry struct {
+	providers []Provider
+	byName    map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]Provider)}
+}
+
+// Register adds p. Names must be unique.
+func (r *Registry) Register(p Provider) error {
+	name := p.Name()
+	if name == "" {
+		return fmt.Errorf("providers: provider name is empty")
+	}
+	if _, ok := r.byName[name]; ok {
+		return fmt.Errorf("providers: provider %q already registered", name)
+	}
+	r.providers = append(r.providers, p)
+	r.byName[name] = p
+	return nil
+}
+
+// Lookup returns the provider registered under name.
+func (r *Registry) Lookup(name string) (Provider, bool) {
+	p, ok := r.byName[name]
+	return p, ok
+}
+
+// Providers returns every registered provider in registration order.
+func (r *Registry) Providers() []Provider {
+	return append([]Provider(nil), r.providers...)
+}
+
+// Validate reports products that configure a provider nobody registered, and
+// settings rejected by a registered ProductValidator.
+func (r *Registry) Validate(products []domain.Product) error {
+	var errs []string
+	for _, p := range products {
+		for _, provider := range r.providers {
+			v, ok := provider.(ProductValidator)
+			if !ok || !provider.Enabled(p) {
+				continue
+			}
+			if err := v.ValidateProduct(p); err != nil {
+				errs = append(errs, fmt.Sprintf("product %q: %s: %v", p.Name, provider.Name(), err))
+			}
+		}
+		names := make([]string, 0, len(p.Providers))
+		for name := range p.Providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, ok := r.byName[name]; !ok {
+				errs = append(errs, fmt.Sprintf("product %q uses unknown provider %q", p.Name, name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("providers: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
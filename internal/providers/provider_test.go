@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// fakeProvider reports a generic value for products with a "fake" block.
+type fakeProvider struct {
+	name   string
+	err    error
+	begins int
+}
+
+func (p *fakeProvider) Name() string  { return p.name }
+func (p *fakeProvider) Label() string { return "Fake" }
+
+func (p *fakeProvider) Enabled(product domain.Product) bool {
+	_, ok := product.Providers[p.name]
+	return ok
+}
+
+func (p *fakeProvider) Begin(context.Context, []domain.Product, Window) CollectFunc {
+	p.begins++
+	return func(_ context.Context, product domain.Product, s *Sample) error {
+		if p.err != nil {
+			return p.err
+		}
+		s.Metrics.SetValue(p.name+".signups", 12)
+		s.Metrics.SetLabel(p.name+".plan", product.Providers[p.name].String("plan"))
+		return nil
+	}
+}
+
+func (p *fakeProvider) Columns([]domain.Product) []Column {
+	return []Column{
+		{Key: p.name + ".signups", Title: "SIGNUPS"},
+		{Key: p.name + ".plan", Title: "PLAN", Format: FormatText},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "keeps registration order",
+			fn: func(t *testing.T) {
+				registry := newTestRegistry(t, &fakeProvider{name: "b"}, &fakeProvider{name: "a"})
+				var names []string
+				for _, p := range registry.Providers() {
+					names = append(names, p.Name())
+				}
+				if !reflect.DeepEqual(names, []string{"b", "a"}) {
+					t.Fatalf("Providers() = %v, want [b a]", names)
+				}
+				if _, ok := registry.Lookup("a"); !ok {
+					t.Fatalf("Lookup(a) ok = false, want true")
+				}
+			},
+		},
+		{
+			name: "rejects duplicate and empty names",
+			fn: func(t *testing.T) {
+				registry := newTestRegistry(t, &fakeProvider{name: "fake"})
+				if err := registry.Register(&fakeProvider{name: "fake"}); err == nil {
+					t.Errorf("Register(duplicate) error = nil, want error")
+				}
+				if err := registry.Register(&fakeProvider{}); err == nil {
+					t.Errorf("Register(empty name) error = nil, want error")
+				}
+			},
+		},
+		{
+			name: "validates product provider names",
+			fn: func(t *testing.T) {
+				registry := newTestRegistry(t, &fakeProvider{name: "fake"})
+				products := []domain.Product{
+					{Name: "App", Providers: map[string]domain.ProviderConfig{"fake": {}}},
+					{Name: "Tool", Providers: map[string]domain.ProviderConfig{"plausible": {}}},
+				}
+				err := registry.Validate(products)
+				if err == nil || !strings.Contains(err.Error(), `product "Tool" uses unknown provider "plausible"`) {
+					t.Fatalf("Validate() error = %v, want unknown provider", err)
+				}
+				if err := registry.Validate(products[:1]); err != nil {
+					t.Fatalf("Validate(known) error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
+
+func TestFetchAllRegisteredProvider(t *testing.T) {
+	fake := &fakeProvider{name: "fake"}
+	broken := &fakeProvider{name: "broken", err: errors.New("boom")}
+	unused := &fakeProvider{name: "unused"}
+	fetcher := NewMetricsFetcher(newTestRegistry(t, fake, broken, unused), nil)
+
+	products := []domain.Product{
+		{Name: "App", Providers: map[string]domain.ProviderConfig{"fake": {"plan": "pro"}, "broken": {}}},
+		{Name: "Plain"},
+	}
+
+	var order []string
+	fetcher.FetchEach(context.Background(), products[:1], func(u Update) {
+		order = append(order, u.Provider)
+	})
+	// Providers run at once, so their updates arrive in either order.
+	slices.Sort(order[:len(order)-1])
+	if want := []string{"broken", "fake", ""}; !reflect.DeepEqual(order, want) {
+		t.Errorf("update providers = %v, want %v", order, want)
+	}
+
+	metrics := fetcher.FetchAll(context.Background(), products)
+	app := metrics["App"]
+	if app.Values["fake.signups"] != 12 || app.Labels["fake.plan"] != "pro" {
+		t.Errorf("App values = %v labels = %v, want fake metrics", app.Values, app.Labels)
+	}
+	if !reflect.DeepEqual(app.Errors, []string{"Fake: boom"}) {
+		t.Errorf("App errors = %v, want broken provider error", app.Errors)
+	}
+	if fake.begins != 2 || unused.begins != 0 {
+		t.Errorf("Begin calls = %d fake, %d unused; want one per refresh, none when unused", fake.begins, unused.begins)
+	}
+	if plain := metrics["Plain"]; len(plain.Values) != 0 || len(plain.Errors) != 0 {
+		t.Errorf("Plain = %+v, want no generic metrics", plain)
+	}
+
+	var keys []string
+	for _, c := range fetcher.Columns(products) {
+		keys = append(keys, c.Provider+":"+c.Key)
+	}
+	want := []string{"fake:fake.signups", "fake:fake.plan", "broken:broken.signups", "broken:broken.plan"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Columns() = %v, want %v (unused provider omitted)", keys, want)
+	}
+}
@@ -2,21 +2,62 @@ package providers
 
 import "github.com/phaedrus/overmind/internal/store"
 
+// Credentials holds the account-level secrets for every provider.
+type Credentials struct {
+	StripeSecretKey string
+
+	PostHogAPIKey    string
+	PostHogProjectID string
+	PostHogHost      string
+
+	SentryAuthToken string
+	SentryOrg       string
+	SentryHost      string
+
+	VercelToken  string
+	VercelTeamID string
+
+	GitHubToken string
+}
+
 type Providers struct {
 	Stripe  *StripeClient
 	PostHog *PostHogClient
+	Sentry  *SentryClient
+	Vercel  *VercelClient
+	GitHub  *GitHubClient
+	HTTP    *HTTPJSONClient
+	RDAP    *RDAPClient
 }
 
-func New(stripeKey, posthogKey, posthogProjectID, posthogHost string) *Providers {
+func New(creds Credentials) *Providers {
 	return &Providers{
-		Stripe:  NewStripeClient(stripeKey),
-		PostHog: NewPostHogClient(posthogKey, posthogProjectID, posthogHost),
+		Stripe:  NewStripeClient(creds.StripeSecretKey),
+		PostHog: NewPostHogClient(creds.PostHogAPIKey, creds.PostHogProjectID, creds.PostHogHost),
+		Sentry:  NewSentryClient(creds.SentryAuthToken, creds.SentryOrg, creds.SentryHost),
+		Vercel:  NewVercelClient(creds.VercelToken, creds.VercelTeamID),
+		GitHub:  NewGitHubClient(creds.GitHubToken),
+		HTTP:    NewHTTPJSONClient(),
+		RDAP:    NewRDAPClient(""),
 	}
 }
 
-func (p *Providers) NewMetricsFetcher(s *store.Store) *MetricsFetcher {
+// Registry returns a registry holding the built-in providers, in the order
+// they run for each product. Providers that keep state between refreshes,
+// such as health check failure counts and domain lookups, keep it in s when
+// it is non-nil.
+func (p *Providers) Registry(s *store.Store) *Registry {
+	registry := NewRegistry()
 	if p == nil {
-		return NewMetricsFetcher(nil, nil, s)
+		return registry
+	}
+	for _, provider := range []Provider{p.PostHog, p.Stripe.withStore(s), p.Sentry, p.Vercel, p.GitHub, p.HTTP, NewHealthChecker(s), NewDomainChecker(p.RDAP, s)} {
+		// Built-in names are distinct constants, so Register cannot fail.
+		_ = registry.Register(provider)
 	}
-	return NewMetricsFetcher(p.Stripe, p.PostHog, s)
+	return registry
+}
+
+func (p *Providers) NewMetricsFetcher(s *store.Store) *MetricsFetcher {
+	return NewMetricsFetcher(p.Registry(s), s)
 }
@@ -0,0 +1,288 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/store"
+)
+
+// DefaultRDAPURL redirects each lookup to the registry that serves the
+// domain's TLD, per the IANA bootstrap file.
+const DefaultRDAPURL = "https://rdap.org"
+
+// domainCacheTTL is how long a registration lookup is reused. Expiry dates
+// move once a year at most, and registries rate-limit RDAP.
+const domainCacheTTL = 24 * time.Hour
+
+// rdapMaxBody caps how much of an RDAP response is read.
+const rdapMaxBody = 1 << 20
+
+// errRDAPNotFound means the registry has no record of the domain, or no
+// RDAP service covers its TLD. Unlike a network failure it is worth caching.
+var errRDAPNotFound = errors.New("no RDAP record")
+
+// RDAPClient looks up domain registrations over RDAP (RFC 9083).
+type RDAPClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewRDAPClient queries baseURL + "/domain/<name>"; an empty baseURL uses
+// DefaultRDAPURL.
+func NewRDAPClient(baseURL string) *RDAPClient {
+	if baseURL == "" {
+		baseURL = DefaultRDAPURL
+	}
+	return &RDAPClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type rdapDomain struct {
+	Events   []rdapEvent  `json:"events"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+type rdapEvent struct {
+	Action string `json:"eventAction"`
+	Date   string `json:"eventDate"`
+}
+
+type rdapEntity struct {
+	Handle   string       `json:"handle"`
+	Roles    []string     `json:"roles"`
+	VCard    []any        `json:"vcardArray"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+// Lookup fetches the registration of a registrable domain. A registry that
+// publishes no expiration event yields a zero ExpiresAt.
+func (c *RDAPClient) Lookup(ctx context.Context, name string) (*domain.DomainRegistration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/domain/"+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, fmt.Errorf("rdap: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rdap: lookup %s: %w", name, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, rdapMaxBody))
+	if err != nil {
+		return nil, fmt.Errorf("rdap: read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("rdap: %s: %w", name, errRDAPNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rdap: lookup %s failed: status %d: %s", name, resp.StatusCode, truncate(string(body), 200))
+	}
+
+	var result rdapDomain
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("rdap: decode response: %w", err)
+	}
+
+	reg := &domain.DomainRegistration{Domain: name, Registrar: rdapRegistrar(result.Entities)}
+	for _, event := range result.Events {
+		if event.Action != "expiration" {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, event.Date)
+		if err != nil {
+			return nil, fmt.Errorf("rdap: %s expiration %q: %w", name, event.Date, err)
+		}
+		reg.ExpiresAt = expiresAt
+	}
+	return reg, nil
+}
+
+// rdapRegistrar returns the name of the entity with the registrar role,
+// falling back to its handle when it has no vCard name.
+func rdapRegistrar(entities []rdapEntity) string {
+	for _, entity := range entities {
+		for _, role := range entity.Roles {
+			if role != "registrar" {
+				continue
+			}
+			if name := vcardName(entity.VCard); name != "" {
+				return name
+			}
+			return entity.Handle
+		}
+		if name := rdapRegistrar(entity.Entities); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// vcardName returns the "fn" property of a jCard (RFC 7095), which looks like
+// ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Name"]]].
+func vcardName(vcard []any) string {
+	if len(vcard) < 2 {
+		return ""
+	}
+	properties, _ := vcard[1].([]any)
+	for _, p := range properties {
+		property, _ := p.([]any)
+		if len(property) < 4 || property[0] != "fn" {
+			continue
+		}
+		name, _ := property[3].(string)
+		return strings.TrimSpace(name)
+	}
+	return ""
+}
+
+// registrableDomain returns the domain a registrar sells for host, e.g.
+// "example.co.uk" for "app.example.co.uk". It reports false for IP addresses
+// and for hosts under a private suffix such as vercel.app, whose
+// registration belongs to the platform rather than the product.
+func registrableDomain(host string) (string, bool) {
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" || net.ParseIP(host) != nil {
+		return "", false
+	}
+	if _, icann := publicsuffix.PublicSuffix(host); !icann {
+		return "", false
+	}
+	name, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// DomainChecker reports when each product's domain registration expires.
+// Lookups are cached in the store for a day, so a refresh loop doesn't query
+// the registry every few minutes.
+type DomainChecker struct {
+	rdap  *RDAPClient
+	store *store.Store
+}
+
+// NewDomainChecker returns a DomainChecker that caches lookups in s, which
+// may be nil to look up on every refresh.
+func NewDomainChecker(rdap *RDAPClient, s *store.Store) DomainChecker {
+	return DomainChecker{rdap: rdap, store: s}
+}
+
+func (DomainChecker) Name() string  { return ProviderDomain }
+func (DomainChecker) Label() string { return "Domain" }
+
+func (DomainChecker) Enabled(p domain.Product) bool {
+	_, ok := registrableDomain(p.Domain)
+	return ok
+}
+
+// domainRenewalKey is the Metrics.Labels key shown in the RENEWAL column.
+const domainRenewalKey = ProviderDomain + ".renewal"
+
+func (DomainChecker) Columns([]domain.Product) []Column {
+	return []Column{{Key: domainRenewalKey, Title: "RENEWAL", Format: FormatText}}
+}
+
+// Begin returns a collector that looks up each registrable domain once per
+// refresh, however many products share it.
+func (c DomainChecker) Begin(ctx context.Context, _ []domain.Product, w Window) CollectFunc {
+	var (
+		mu      sync.Mutex
+		lookups = make(map[string]func() (*domain.DomainRegistration, error))
+	)
+	return func(_ context.Context, p domain.Product, s *Sample) error {
+		name, _ := registrableDomain(p.Domain)
+		mu.Lock()
+		lookup, ok := lookups[name]
+		if !ok {
+			lookup = sync.OnceValues(func() (*domain.DomainRegistration, error) {
+				return c.registration(ctx, name, w.Now)
+			})
+			lookups[name] = lookup
+		}
+		mu.Unlock()
+
+		reg, err := lookup()
+		if reg == nil {
+			return err
+		}
+		applyRegistration(s.Metrics, reg, w.Now)
+		return err
+	}
+}
+
+// registration returns name's cached registration while it is fresh, and
+// otherwise looks it up. When a lookup fails transiently the stale entry is
+// returned along with the error.
+func (c DomainChecker) registration(ctx context.Context, name string, now time.Time) (*domain.DomainRegistration, error) {
+	var cached *domain.DomainRegistration
+	if c.store != nil {
+		var err error
+		if cached, err = c.store.GetDomainRegistration(ctx, name); err != nil {
+			return nil, err
+		}
+		if cached != nil && now.Sub(cached.CheckedAt) < domainCacheTTL {
+			return cached, nil
+		}
+	}
+
+	reg, err := c.rdap.Lookup(ctx, name)
+	switch {
+	case errors.Is(err, errRDAPNotFound):
+		reg = &domain.DomainRegistration{Domain: name, Error: fmt.Sprintf("%s: %v", name, errRDAPNotFound)}
+	case err != nil:
+		return cached, err
+	}
+	reg.CheckedAt = now
+	if c.store != nil {
+		if err := c.store.SaveDomainRegistration(ctx, *reg); err != nil {
+			return reg, err
+		}
+	}
+	return reg, nil
+}
+
+// applyRegistration copies reg into m and sets the RENEWAL label: days left,
+// "expired", or "no RDAP" for a TLD the lookup can't cover. That is how the
+// TLD is, not a failure, so it isn't reported as an error.
+func applyRegistration(m *domain.Metrics, reg *domain.DomainRegistration, now time.Time) {
+	if reg.Error != "" {
+		m.SetLabel(domainRenewalKey, "no RDAP")
+		return
+	}
+	if reg.Registrar != "" {
+		m.SetLabel(domain.DomainRegistrarKey, reg.Registrar)
+	}
+	if reg.ExpiresAt.IsZero() {
+		return
+	}
+	m.SetValue(domain.DomainExpiresKey, float64(reg.ExpiresAt.Unix()))
+	if !reg.ExpiresAt.After(now) {
+		m.SetLabel(domainRenewalKey, "expired")
+		return
+	}
+	days := int(reg.ExpiresAt.Sub(now).Hours() / 24)
+	m.SetLabel(domainRenewalKey, fmt.Sprintf("%dd", days))
+}
@@ -0,0 +1,257 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/store"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		host   string
+		want   string
+		wantOK bool
+	}{
+		{host: "example.com", want: "example.com", wantOK: true},
+		{host: "App.Example.com.", want: "example.com", wantOK: true},
+		{host: "shop.example.co.uk", want: "example.co.uk", wantOK: true},
+		{host: "example.com:8443", want: "example.com", wantOK: true},
+		{host: "myapp.vercel.app"},
+		{host: "localhost"},
+		{host: "127.0.0.1"},
+		{host: "com"},
+		{host: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, ok := registrableDomain(tt.host)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("registrableDomain(%q) = %q, %v, want %q, %v", tt.host, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// rdapStandIn serves RDAP domain objects from records, keyed by name, and
+// counts the lookups it answers.
+func rdapStandIn(t *testing.T, records map[string]string) (*RDAPClient, *atomic.Int32) {
+	t.Helper()
+	var lookups atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		record, ok := records[strings.TrimPrefix(r.URL.Path, "/domain/")]
+		switch {
+		case r.Header.Get("Accept") != "application/rdap+json":
+			http.Error(w, "bad accept", http.StatusNotAcceptable)
+		case !ok:
+			http.Error(w, `{"errorCode": 404}`, http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "application/rdap+json")
+			_, _ = w.Write([]byte(record))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return NewRDAPClient(srv.URL + "/"), &lookups
+}
+
+const exampleRDAP = `{
+	"objectClassName": "domain",
+	"ldhName": "EXAMPLE.COM",
+	"events": [
+		{"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"},
+		{"eventAction": "expiration", "eventDate": "2026-04-10T04:00:00Z"}
+	],
+	"entities": [
+		{"roles": ["technical"], "handle": "T1"},
+		{
+			"roles": ["registrar"],
+			"handle": "376",
+			"vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "RESERVED-IANA"]]]
+		}
+	]
+}`
+
+func TestRDAPClientLookup(t *testing.T) {
+	client, _ := rdapStandIn(t, map[string]string{
+		"example.com": exampleRDAP,
+		"example.de":  `{"entities": [{"roles": ["registrar"], "handle": "DENIC"}]}`,
+		"broken.com":  `{"events": [{"eventAction": "expiration", "eventDate": "soon"}]}`,
+	})
+
+	tests := []struct {
+		name    string
+		domain  string
+		want    domain.DomainRegistration
+		wantErr string
+	}{
+		{
+			name:   "expiry and registrar name",
+			domain: "example.com",
+			want:   domain.DomainRegistration{Domain: "example.com", Registrar: "RESERVED-IANA", ExpiresAt: time.Date(2026, 4, 10, 4, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:   "registrar handle without expiry",
+			domain: "example.de",
+			want:   domain.DomainRegistration{Domain: "example.de", Registrar: "DENIC"},
+		},
+		{name: "unknown domain", domain: "missing.com", wantErr: "missing.com: no RDAP record"},
+		{name: "bad expiration date", domain: "broken.com", wantErr: `expiration "soon"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Lookup(context.Background(), tt.domain)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Lookup(%q) error = %v, want %q", tt.domain, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup(%q) error = %v", tt.domain, err)
+			}
+			if *got != tt.want {
+				t.Errorf("Lookup(%q) = %+v, want %+v", tt.domain, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainChecker(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	collect := func(t *testing.T, checker DomainChecker, at time.Time, products ...domain.Product) ([]*domain.Metrics, []error) {
+		t.Helper()
+		c := checker.Begin(context.Background(), products, Window{Now: at})
+		var metrics []*domain.Metrics
+		var errs []error
+		for _, p := range products {
+			s := &Sample{Metrics: &domain.Metrics{ProductName: p.Name, Timestamp: at}}
+			errs = append(errs, c(context.Background(), p, s))
+			metrics = append(metrics, s.Metrics)
+		}
+		return metrics, errs
+	}
+	openStore := func(t *testing.T) *store.Store {
+		t.Helper()
+		s, err := store.Open(":memory:")
+		if err != nil {
+			t.Fatalf("store.Open() error = %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	}
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "reports expiry, renewal label and signal",
+			fn: func(t *testing.T) {
+				client, _ := rdapStandIn(t, map[string]string{"example.com": exampleRDAP})
+				metrics, errs := collect(t, NewDomainChecker(client, nil), now, domain.Product{Name: "App", Domain: "app.example.com"})
+				if errs[0] != nil {
+					t.Fatalf("collect() error = %v", errs[0])
+				}
+				m := metrics[0]
+				if m.DomainRegistrar() != "RESERVED-IANA" || !m.DomainExpiresAt().Equal(time.Date(2026, 4, 10, 4, 0, 0, 0, time.UTC)) {
+					t.Errorf("registration = %q, %v, want RESERVED-IANA expiring 2026-04-10", m.DomainRegistrar(), m.DomainExpiresAt())
+				}
+				if got := m.Labels[domainRenewalKey]; got != "20d" {
+					t.Errorf("renewal label = %q, want 20d", got)
+				}
+				if got := m.Warnings(); len(got) != 1 || got[0] != domain.SignalDomainExpiring {
+					t.Errorf("Warnings() = %v, want domain_expiring", got)
+				}
+			},
+		},
+		{
+			name: "looks up a shared domain once per refresh and caches it for a day",
+			fn: func(t *testing.T) {
+				client, lookups := rdapStandIn(t, map[string]string{"example.com": exampleRDAP})
+				checker := NewDomainChecker(client, openStore(t))
+				products := []domain.Product{
+					{Name: "App", Domain: "app.example.com"},
+					{Name: "Docs", Domain: "docs.example.com"},
+				}
+
+				collect(t, checker, now, products...)
+				if got := lookups.Load(); got != 1 {
+					t.Fatalf("lookups after first refresh = %d, want 1", got)
+				}
+				metrics, _ := collect(t, checker, now.Add(23*time.Hour), products...)
+				if got := lookups.Load(); got != 1 {
+					t.Fatalf("lookups within a day = %d, want 1", got)
+				}
+				if metrics[1].DomainRegistrar() != "RESERVED-IANA" {
+					t.Errorf("cached registrar = %q, want RESERVED-IANA", metrics[1].DomainRegistrar())
+				}
+				collect(t, checker, now.Add(25*time.Hour), products...)
+				if got := lookups.Load(); got != 2 {
+					t.Fatalf("lookups after a day = %d, want 2", got)
+				}
+			},
+		},
+		{
+			name: "labels and caches missing records but not transient failures",
+			fn: func(t *testing.T) {
+				client, lookups := rdapStandIn(t, nil)
+				checker := NewDomainChecker(client, openStore(t))
+				p := domain.Product{Name: "App", Domain: "missing.com"}
+
+				for i := range 2 {
+					metrics, errs := collect(t, checker, now.Add(time.Duration(i)*time.Hour), p)
+					if errs[0] != nil {
+						t.Fatalf("collect() error = %v, want none for a missing record", errs[0])
+					}
+					if got := metrics[0].Labels[domainRenewalKey]; got != "no RDAP" {
+						t.Errorf("renewal label = %q, want no RDAP", got)
+					}
+				}
+				if got := lookups.Load(); got != 1 {
+					t.Errorf("lookups = %d, want 1", got)
+				}
+
+				down := NewDomainChecker(NewRDAPClient("http://127.0.0.1:1"), openStore(t))
+				for range 2 {
+					if _, errs := collect(t, down, now, p); errs[0] == nil {
+						t.Fatalf("collect() error = nil, want connection error")
+					}
+				}
+			},
+		},
+		{
+			name: "expired registration",
+			fn: func(t *testing.T) {
+				client, _ := rdapStandIn(t, map[string]string{"example.com": exampleRDAP})
+				metrics, _ := collect(t, NewDomainChecker(client, nil), now.AddDate(0, 1, 0), domain.Product{Name: "App", Domain: "example.com"})
+				if got := metrics[0].Labels[domainRenewalKey]; got != "expired" {
+					t.Errorf("renewal label = %q, want expired", got)
+				}
+			},
+		},
+		{
+			name: "skips hosts without a registrable domain",
+			fn: func(t *testing.T) {
+				checker := NewDomainChecker(NewRDAPClient(""), nil)
+				for _, host := range []string{"", "localhost", "myapp.vercel.app"} {
+					if checker.Enabled(domain.Product{Domain: host}) {
+						t.Errorf("Enabled(%q) = true, want false", host)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
@@ -0,0 +1,213 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+const sentryDefaultHost = "https://sentry.io"
+
+// sentryMaxPages bounds issue pagination when the API omits X-Hits.
+const sentryMaxPages = 50
+
+type SentryClient struct {
+	authToken  string
+	org        string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewSentryClient(authToken, org, host string) *SentryClient {
+	host = strings.TrimRight(strings.TrimSpace(host), "/")
+	if host == "" {
+		host = sentryDefaultHost
+	}
+	return &SentryClient{
+		authToken:  authToken,
+		org:        org,
+		baseURL:    host,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type SentryStats struct {
+	UnresolvedIssues int64
+	Events24h        int64
+	NewIssues        int64 // first seen in the last 7 days
+}
+
+// GetProjectStats returns issue and event counts for a Sentry project slug.
+func (c *SentryClient) GetProjectStats(ctx context.Context, project string) (*SentryStats, error) {
+	if c.authToken == "" {
+		return nil, fmt.Errorf("sentry: auth token is empty")
+	}
+	if project == "" {
+		return nil, fmt.Errorf("sentry: project is empty")
+	}
+
+	unresolved, err := c.countIssues(ctx, project, "is:unresolved")
+	if err != nil {
+		return nil, err
+	}
+
+	newIssues, err := c.countIssues(ctx, project, "firstSeen:-7d")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	events, err := c.countEvents(ctx, project, now.Add(-24*time.Hour), now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SentryStats{
+		UnresolvedIssues: unresolved,
+		Events24h:        events,
+		NewIssues:        newIssues,
+	}, nil
+}
+
+// countIssues counts issues matching a search query. Sentry reports the total
+// in the X-Hits header; when absent we fall back to walking the cursor.
+func (c *SentryClient) countIssues(ctx context.Context, project, query string) (int64, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("statsPeriod", "")
+	params.Set("limit", "100")
+	endpoint := fmt.Sprintf("%s/api/0/projects/%s/%s/issues/?%s",
+		c.baseURL, url.PathEscape(c.org), url.PathEscape(project), params.Encode())
+
+	var total int64
+	for page := 0; page < sentryMaxPages; page++ {
+		resp, err := c.get(ctx, endpoint)
+		if err != nil {
+			return 0, fmt.Errorf("sentry: list issues: %w", err)
+		}
+
+		if hits := resp.Header.Get("X-Hits"); hits != "" {
+			_ = resp.Body.Close()
+			n, err := strconv.ParseInt(hits, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("sentry: parse X-Hits %q: %w", hits, err)
+			}
+			return n, nil
+		}
+
+		var issues []json.RawMessage
+		err = json.NewDecoder(resp.Body).Decode(&issues)
+		_ = resp.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("sentry: decode issues: %w", err)
+		}
+		total += int64(len(issues))
+
+		next := nextSentryCursor(resp.Header.Get("Link"))
+		if next == "" {
+			return total, nil
+		}
+		// Never send the auth token to a host other than the configured one.
+		if !strings.HasPrefix(next, c.baseURL+"/") {
+			return 0, fmt.Errorf("sentry: unexpected pagination url %q", next)
+		}
+		endpoint = next
+	}
+	return total, fmt.Errorf("sentry: issue pagination exceeded %d pages", sentryMaxPages)
+}
+
+// countEvents sums received events for a project between from and to.
+func (c *SentryClient) countEvents(ctx context.Context, project string, from, to time.Time) (int64, error) {
+	params := url.Values{}
+	params.Set("stat", "received")
+	params.Set("since", strconv.FormatInt(from.Unix(), 10))
+	params.Set("until", strconv.FormatInt(to.Unix(), 10))
+	params.Set("resolution", "1h")
+	endpoint := fmt.Sprintf("%s/api/0/projects/%s/%s/stats/?%s",
+		c.baseURL, url.PathEscape(c.org), url.PathEscape(project), params.Encode())
+
+	resp, err := c.get(ctx, endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("sentry: project stats: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// Response is a list of [timestamp, count] pairs.
+	var points [][2]float64
+	if err := json.NewDecoder(resp.Body).Decode(&points); err != nil {
+		return 0, fmt.Errorf("sentry: decode stats: %w", err)
+	}
+
+	var total int64
+	for _, point := range points {
+		total += int64(point[1])
+	}
+	return total, nil
+}
+
+func (c *SentryClient) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.authToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// nextSentryCursor extracts the next page URL from a Sentry Link header.
+// Sentry always emits a rel="next" link and marks whether it has results:
+//
+//	<https://sentry.io/...&cursor=0:100:0>; rel="next"; results="true"; cursor="0:100:0"
+func nextSentryCursor(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		part = strings.TrimSpace(part)
+		if !strings.Contains(part, `rel="next"`) || !strings.Contains(part, `results="true"`) {
+			continue
+		}
+		start := strings.Index(part, "<")
+		end := strings.Index(part, ">")
+		if start == -1 || end <= start {
+			continue
+		}
+		return part[start+1 : end]
+	}
+	return ""
+}
+
+func (c *SentryClient) Name() string  { return ProviderSentry }
+func (c *SentryClient) Label() string { return "Sentry" }
+
+func (c *SentryClient) Enabled(p domain.Product) bool { return p.SentryProject != "" }
+
+func (c *SentryClient) Begin(context.Context, []domain.Product, Window) CollectFunc {
+	return func(ctx context.Context, p domain.Product, s *Sample) error {
+		stats, err := c.GetProjectStats(ctx, p.SentryProject)
+		if err != nil {
+			return err
+		}
+		s.Metrics.UnresolvedIssues = stats.UnresolvedIssues
+		s.Metrics.ErrorEvents = stats.Events24h
+		s.Metrics.NewIssues = stats.NewIssues
+		return nil
+	}
+}
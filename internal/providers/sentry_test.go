@@ -0,0 +1,146 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSentryGetProjectStats(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "reads X-Hits and sums event stats",
+			fn: func(t *testing.T) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+						t.Errorf("Authorization = %q, want bearer token", got)
+					}
+					switch r.URL.Path {
+					case "/api/0/projects/org/app/issues/":
+						switch r.URL.Query().Get("query") {
+						case "is:unresolved":
+							w.Header().Set("X-Hits", "42")
+						case "firstSeen:-7d":
+							w.Header().Set("X-Hits", "3")
+						default:
+							t.Errorf("unexpected query %q", r.URL.Query().Get("query"))
+						}
+						_, _ = w.Write([]byte(`[{}]`))
+					case "/api/0/projects/org/app/stats/":
+						_, _ = w.Write([]byte(`[[1700000000, 5], [1700003600, 7]]`))
+					default:
+						http.NotFound(w, r)
+					}
+				}))
+				defer server.Close()
+
+				client := NewSentryClient("tok", "org", server.URL)
+				got, err := client.GetProjectStats(context.Background(), "app")
+				if err != nil {
+					t.Fatalf("GetProjectStats() error = %v", err)
+				}
+				want := SentryStats{UnresolvedIssues: 42, Events24h: 12, NewIssues: 3}
+				if *got != want {
+					t.Fatalf("GetProjectStats() = %+v, want %+v", *got, want)
+				}
+			},
+		},
+		{
+			name: "follows cursor when X-Hits missing",
+			fn: func(t *testing.T) {
+				var server *httptest.Server
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/api/0/projects/org/app/stats/" {
+						_, _ = w.Write([]byte(`[]`))
+						return
+					}
+					issues := make([]map[string]string, 100)
+					if r.URL.Query().Get("cursor") == "" {
+						next := server.URL + r.URL.Path + "?" + r.URL.RawQuery + "&cursor=0:100:0"
+						w.Header().Set("Link", `<`+next+`>; rel="next"; results="true"; cursor="0:100:0"`)
+					} else {
+						issues = issues[:5]
+						w.Header().Set("Link", `<`+server.URL+`/x>; rel="next"; results="false"; cursor="0:200:0"`)
+					}
+					_ = json.NewEncoder(w).Encode(issues)
+				}))
+				defer server.Close()
+
+				client := NewSentryClient("tok", "org", server.URL)
+				got, err := client.GetProjectStats(context.Background(), "app")
+				if err != nil {
+					t.Fatalf("GetProjectStats() error = %v", err)
+				}
+				if got.UnresolvedIssues != 105 {
+					t.Fatalf("UnresolvedIssues = %d, want 105", got.UnresolvedIssues)
+				}
+			},
+		},
+		{
+			name: "surfaces api errors",
+			fn: func(t *testing.T) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, `{"detail":"Invalid token"}`, http.StatusUnauthorized)
+				}))
+				defer server.Close()
+
+				client := NewSentryClient("tok", "org", server.URL)
+				_, err := client.GetProjectStats(context.Background(), "app")
+				if err == nil || !strings.Contains(err.Error(), "status 401") {
+					t.Fatalf("GetProjectStats() error = %v, want status 401", err)
+				}
+			},
+		},
+		{
+			name: "requires auth token",
+			fn: func(t *testing.T) {
+				client := NewSentryClient("", "org", "")
+				if _, err := client.GetProjectStats(context.Background(), "app"); err == nil {
+					t.Fatalf("GetProjectStats() error = nil, want error")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
+
+func TestNextSentryCursor(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want string
+	}{
+		{
+			name: "next with results",
+			link: `<https://sentry.io/a?cursor=0:0:1>; rel="previous"; results="false"; cursor="0:0:1", <https://sentry.io/a?cursor=0:100:0>; rel="next"; results="true"; cursor="0:100:0"`,
+			want: "https://sentry.io/a?cursor=0:100:0",
+		},
+		{
+			name: "next without results",
+			link: `<https://sentry.io/a?cursor=0:100:0>; rel="next"; results="false"; cursor="0:100:0"`,
+			want: "",
+		},
+		{
+			name: "empty header",
+			link: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextSentryCursor(tt.link); got != tt.want {
+				t.Errorf("nextSentryCursor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,34 +5,56 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/store"
 )
 
 const stripeBaseURL = "https://api.stripe.com/v1"
 
 type StripeClient struct {
 	secretKey  string
+	baseURL    string
 	httpClient *http.Client
+	store      *store.Store // tracks per-subscription MRR for expansion and contraction; may be nil
 }
 
 func NewStripeClient(secretKey string) *StripeClient {
 	return &StripeClient{
 		secretKey:  secretKey,
+		baseURL:    stripeBaseURL,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
+// withStore returns a copy of c that tracks per-subscription MRR in s.
+func (c *StripeClient) withStore(s *store.Store) *StripeClient {
+	clone := *c
+	clone.store = s
+	return &clone
+}
+
 type stripeSubscriptionList struct {
 	Data    []stripeSubscription `json:"data"`
 	HasMore bool                 `json:"has_more"`
 }
 
 type stripeSubscription struct {
-	ID    string                  `json:"id"`
-	Items stripeSubscriptionItems `json:"items"`
+	ID        string                  `json:"id"`
+	Status    string                  `json:"status"`     // active, canceled, trialing, past_due, ...
+	StartDate int64                   `json:"start_date"` // unix seconds
+	TrialEnd  int64                   `json:"trial_end"`  // 0 without a trial
+	EndedAt   int64                   `json:"ended_at"`   // 0 until the subscription ends
+	Discount  *stripeDiscount         `json:"discount"`   // single discount on API versions without discounts
+	Discounts []stripeDiscount        `json:"discounts"`
+	Items     stripeSubscriptionItems `json:"items"`
 }
 
 type stripeSubscriptionItems struct {
@@ -40,8 +62,9 @@ type stripeSubscriptionItems struct {
 }
 
 type stripeSubscriptionItem struct {
-	Price    stripePrice `json:"price"`
-	Quantity *int64      `json:"quantity"`
+	Price     stripePrice      `json:"price"`
+	Quantity  *int64           `json:"quantity"`
+	Discounts []stripeDiscount `json:"discounts"`
 }
 
 type stripePrice struct {
@@ -51,108 +74,494 @@ type stripePrice struct {
 }
 
 type stripePriceRecurring struct {
-	Interval string `json:"interval"` // day, week, month, year
+	Interval      string `json:"interval"` // day, week, month, year
+	IntervalCount int64  `json:"interval_count"`
+}
+
+type stripeDiscount struct {
+	Coupon stripeCoupon `json:"coupon"`
+	Start  int64        `json:"start"` // unix seconds
+	End    int64        `json:"end"`   // unix seconds; 0 for once and forever coupons
+}
+
+type stripeCoupon struct {
+	PercentOff       float64 `json:"percent_off"`
+	AmountOff        int64   `json:"amount_off"` // cents off each invoice
+	Duration         string  `json:"duration"`   // once, repeating, forever
+	DurationInMonths int64   `json:"duration_in_months"`
+}
+
+// StripeRevenue is the recurring revenue attributed to one Stripe product.
+type StripeRevenue struct {
+	MRR         int64 // cents
+	Subscribers int64 // active subscriptions with at least one item for the product
+}
+
+// GetRevenueByProduct lists live subscriptions once and partitions MRR (in
+// cents) and subscriber counts of the active ones by Stripe product ID.
+// Callers that need several products should share one call rather than
+// scanning per product.
+func (c *StripeClient) GetRevenueByProduct(ctx context.Context) (map[string]StripeRevenue, error) {
+	subs, err := c.listAllSubscriptions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return revenueByProduct(subs, time.Now()), nil
+}
+
+// listSubscriptionsEndedSince lists every subscription that hasn't been
+// canceled, plus the canceled ones whose current period ended at or after
+// since. A subscription canceled at the end of its period ends with it and
+// one canceled immediately ends before it, so every subscription that ended
+// since is among them, without paging through the whole canceled history.
+func (c *StripeClient) listSubscriptionsEndedSince(ctx context.Context, since time.Time) ([]stripeSubscription, error) {
+	subs, err := c.listAllSubscriptions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	canceled, err := c.listAllSubscriptions(ctx, url.Values{
+		"status":                  {"canceled"},
+		"current_period_end[gte]": {strconv.FormatInt(since.Unix(), 10)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(subs, canceled...), nil
 }
 
-// GetMRRForProduct returns MRR in cents and active subscriber count for a Stripe product.
-func (c *StripeClient) GetMRRForProduct(ctx context.Context, productID string) (int64, int64, error) {
-	if productID == "" {
-		return 0, 0, fmt.Errorf("stripe: product id is empty")
+// listAllSubscriptions pages through every subscription matching filter.
+// Stripe leaves canceled subscriptions out unless filter asks for them.
+func (c *StripeClient) listAllSubscriptions(ctx context.Context, filter url.Values) ([]stripeSubscription, error) {
+	if c.secretKey == "" {
+		return nil, fmt.Errorf("stripe: secret key is empty")
 	}
 
 	var (
-		mrr           int64
+		subs          []stripeSubscription
 		startingAfter string
-		subscribers   = make(map[string]struct{})
 	)
-
 	for {
-		params := url.Values{}
-		params.Set("status", "active")
-		params.Set("limit", "100")
-		params.Add("expand[]", "data.items.data.price")
-		if startingAfter != "" {
-			params.Set("starting_after", startingAfter)
+		list, err := c.listSubscriptions(ctx, filter, startingAfter)
+		if err != nil {
+			return nil, err
 		}
+		subs = append(subs, list.Data...)
 
-		endpoint := stripeBaseURL + "/subscriptions?" + params.Encode()
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-		if err != nil {
-			return 0, 0, fmt.Errorf("stripe: build request: %w", err)
+		if !list.HasMore {
+			return subs, nil
+		}
+		if len(list.Data) == 0 {
+			return nil, fmt.Errorf("stripe: pagination returned empty page")
+		}
+		startingAfter = list.Data[len(list.Data)-1].ID
+		if startingAfter == "" {
+			return nil, fmt.Errorf("stripe: pagination missing last id")
 		}
-		req.SetBasicAuth(c.secretKey, "")
+	}
+}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return 0, 0, fmt.Errorf("stripe: list subscriptions: %w", err)
+// revenueByProduct partitions the MRR, net of discounts at now, and
+// subscriber counts of active subscriptions by Stripe product ID. A
+// subscription discounted to nothing still counts as a subscriber.
+func revenueByProduct(subs []stripeSubscription, now time.Time) map[string]StripeRevenue {
+	revenue := make(map[string]StripeRevenue)
+	for _, sub := range subs {
+		if sub.Status != "active" {
+			continue
+		}
+		for product, mrr := range subscriptionMRR(sub, now) {
+			r := revenue[product]
+			r.MRR += mrr
+			r.Subscribers++
+			revenue[product] = r
+		}
+	}
+	return revenue
+}
+
+func (c *StripeClient) listSubscriptions(ctx context.Context, filter url.Values, startingAfter string) (*stripeSubscriptionList, error) {
+	params := url.Values{}
+	for key, values := range filter {
+		params[key] = values
+	}
+	params.Set("limit", "100")
+	params.Add("expand[]", "data.items.data.price")
+	params.Add("expand[]", "data.discounts")
+	params.Add("expand[]", "data.items.data.discounts")
+	if startingAfter != "" {
+		params.Set("starting_after", startingAfter)
+	}
+
+	endpoint := c.baseURL + "/subscriptions?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("stripe: build request: %w", err)
+	}
+	req.SetBasicAuth(c.secretKey, "")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stripe: list subscriptions: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return nil, fmt.Errorf("stripe: list subscriptions: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var list stripeSubscriptionList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("stripe: decode subscriptions: %w", err)
+	}
+	return &list, nil
+}
+
+// monthlyAmount returns a line item's recurring amount normalized to one month.
+func monthlyAmount(item stripeSubscriptionItem) int64 {
+	if item.Price.UnitAmount == nil {
+		return 0
+	}
+
+	// Quantity defaults to 1 if not set
+	qty := int64(1)
+	if item.Quantity != nil {
+		qty = *item.Quantity
+	}
+
+	// Calculate line item amount
+	return perMonth(*item.Price.UnitAmount*qty, item.Price.Recurring)
+}
+
+// perMonth normalizes an amount billed every interval_count intervals to
+// one month.
+func perMonth(amount int64, recurring *stripePriceRecurring) int64 {
+	if recurring == nil {
+		return amount
+	}
+	count := max(recurring.IntervalCount, 1)
+	switch recurring.Interval {
+	case "year":
+		return amount / (12 * count)
+	case "week":
+		return amount * 52 / (12 * count)
+	case "day":
+		return amount * 365 / (12 * count)
+	default: // month
+		return amount / count
+	}
+}
+
+// activeAt reports whether d reduces what is billed at t. A once coupon
+// covers the first billing period after it was applied, a repeating one its
+// duration in months, and a forever one every period until it is removed.
+func (d stripeDiscount) activeAt(t time.Time, recurring *stripePriceRecurring) bool {
+	start := time.Unix(d.Start, 0)
+	if t.Before(start) {
+		return false
+	}
+	if d.End > 0 {
+		return t.Before(time.Unix(d.End, 0))
+	}
+	switch d.Coupon.Duration {
+	case "once":
+		return t.Before(billingPeriodEnd(start, recurring))
+	case "repeating":
+		return t.Before(start.AddDate(0, int(d.Coupon.DurationInMonths), 0))
+	default:
+		return true
+	}
+}
+
+// billingPeriodEnd returns when the billing period that starts at start ends.
+func billingPeriodEnd(start time.Time, recurring *stripePriceRecurring) time.Time {
+	if recurring == nil {
+		return start.AddDate(0, 1, 0)
+	}
+	n := int(max(recurring.IntervalCount, 1))
+	switch recurring.Interval {
+	case "day":
+		return start.AddDate(0, 0, n)
+	case "week":
+		return start.AddDate(0, 0, 7*n)
+	case "year":
+		return start.AddDate(n, 0, 0)
+	default:
+		return start.AddDate(0, n, 0)
+	}
+}
+
+// applyDiscounts takes the discounts active at t off a monthly amount, one
+// after another, without going below zero. Amounts off are per billing
+// period, so they are normalized to a month like the price.
+func applyDiscounts(amount int64, discounts []stripeDiscount, recurring *stripePriceRecurring, t time.Time) int64 {
+	for _, d := range discounts {
+		if !d.activeAt(t, recurring) {
+			continue
+		}
+		if d.Coupon.PercentOff > 0 {
+			amount -= int64(math.Round(float64(amount) * d.Coupon.PercentOff / 100))
+		}
+		amount -= perMonth(d.Coupon.AmountOff, recurring)
+		amount = max(amount, 0)
+	}
+	return amount
+}
+
+// stripeMovementDays is how many UTC days, including today, of new and
+// churned MRR each refresh recomputes from subscription dates. They don't
+// depend on earlier refreshes, so the history fills in on the first one.
+const stripeMovementDays = 30
+
+// stripeMovement is one Stripe product's MRR movement on one UTC day, in
+// cents. Contraction and churned are positive amounts lost.
+type stripeMovement struct {
+	new, expansion, contraction, churned int64
+	newSubs, canceledSubs                int64
+}
+
+// paidFrom returns when sub started paying: the end of its trial, or its
+// start without one. ok is false for subscriptions that haven't paid yet or
+// never will, such as one still in its trial or canceled during it.
+func paidFrom(sub stripeSubscription, now time.Time) (time.Time, bool) {
+	if sub.Status != "active" && sub.Status != "canceled" {
+		return time.Time{}, false
+	}
+	from := max(sub.StartDate, sub.TrialEnd)
+	if from == 0 || from > now.Unix() || (sub.EndedAt > 0 && sub.EndedAt <= from) {
+		return time.Time{}, false
+	}
+	return time.Unix(from, 0), true
+}
+
+// subscriptionMRR returns sub's MRR per Stripe product, net of the discounts
+// active at t. An item's own discounts replace the subscription's for that
+// item. The subscription's discounts apply to the rest of the invoice as a
+// whole, so an amount off is shared among those items by their amounts.
+func subscriptionMRR(sub stripeSubscription, t time.Time) map[string]int64 {
+	discounts := sub.Discounts
+	if len(discounts) == 0 && sub.Discount != nil {
+		discounts = []stripeDiscount{*sub.Discount}
+	}
+
+	var (
+		amounts   = make([]int64, len(sub.Items.Data))
+		shared    []int // items the subscription's discounts apply to
+		gross     int64 // their combined monthly amount
+		recurring *stripePriceRecurring
+	)
+	for i, item := range sub.Items.Data {
+		if item.Price.Product == "" || item.Price.UnitAmount == nil {
+			continue
+		}
+		amounts[i] = monthlyAmount(item)
+		if len(item.Discounts) > 0 {
+			amounts[i] = applyDiscounts(amounts[i], item.Discounts, item.Price.Recurring, t)
+			continue
+		}
+		shared = append(shared, i)
+		gross += amounts[i]
+		// Stripe bills every item of a subscription on the same interval.
+		recurring = item.Price.Recurring
+	}
+	if len(discounts) > 0 && gross > 0 {
+		net := applyDiscounts(gross, discounts, recurring, t)
+		remaining := net
+		for n, i := range shared {
+			if n == len(shared)-1 {
+				amounts[i] = remaining
+				break
+			}
+			amounts[i] = amounts[i] * net / gross
+			remaining -= amounts[i]
 		}
+	}
 
-		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
-			_ = resp.Body.Close()
-			return 0, 0, fmt.Errorf("stripe: list subscriptions: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	mrr := make(map[string]int64)
+	for i, item := range sub.Items.Data {
+		if item.Price.Product == "" || item.Price.UnitAmount == nil {
+			continue
 		}
+		mrr[item.Price.Product] += amounts[i]
+	}
+	return mrr
+}
 
-		var list stripeSubscriptionList
-		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
-			_ = resp.Body.Close()
-			return 0, 0, fmt.Errorf("stripe: decode subscriptions: %w", err)
+// movementsByProduct returns each Stripe product's new and churned MRR, with
+// new and canceled subscription counts, for each of the days UTC days ending
+// today, oldest first. New MRR is what a subscription paid when it started,
+// and churned MRR what it paid when it ended, discounts included; a
+// canceled subscription's items are those it had at the end.
+func movementsByProduct(subs []stripeSubscription, now time.Time, days int) map[string][]stripeMovement {
+	first := domain.StartOfDayUTC(now).AddDate(0, 0, -(days - 1))
+	dayIndex := func(t time.Time) (int, bool) {
+		if t.Before(first) {
+			return 0, false
 		}
-		_ = resp.Body.Close()
+		i := int(t.Sub(first) / (24 * time.Hour))
+		return i, i < days
+	}
 
-		for _, sub := range list.Data {
-			matched := false
-			for _, item := range sub.Items.Data {
-				if item.Price.Product != productID {
-					continue
-				}
-				if item.Price.UnitAmount == nil {
-					continue
-				}
-
-				// Quantity defaults to 1 if not set
-				qty := int64(1)
-				if item.Quantity != nil {
-					qty = *item.Quantity
-				}
-
-				// Calculate line item amount
-				amount := *item.Price.UnitAmount * qty
-
-				// Normalize to monthly based on billing interval
-				if item.Price.Recurring != nil {
-					switch item.Price.Recurring.Interval {
-					case "year":
-						amount = amount / 12
-					case "quarter":
-						amount = amount / 3
-					case "week":
-						amount = amount * 52 / 12
-					case "day":
-						amount = amount * 365 / 12
-						// "month" is already monthly, no change needed
-					}
-				}
-
-				mrr += amount
-				matched = true
+	movements := make(map[string][]stripeMovement)
+	for _, sub := range subs {
+		start, ok := paidFrom(sub, now)
+		if !ok {
+			continue
+		}
+		startDay, started := dayIndex(start)
+		if started {
+			for product, mrr := range subscriptionMRR(sub, start) {
+				m := productMovements(movements, product, days)
+				m[startDay].new += mrr
+				m[startDay].newSubs++
 			}
-			if matched {
-				subscribers[sub.ID] = struct{}{}
+		}
+		if sub.Status != "canceled" || sub.EndedAt == 0 {
+			continue
+		}
+		end := time.Unix(sub.EndedAt, 0)
+		if endDay, ended := dayIndex(end); ended {
+			// Discounts are judged just before the end, while it still paid.
+			for product, mrr := range subscriptionMRR(sub, end.Add(-time.Second)) {
+				m := productMovements(movements, product, days)
+				m[endDay].churned += mrr
+				m[endDay].canceledSubs++
 			}
 		}
+	}
+	return movements
+}
 
-		if !list.HasMore {
-			break
+// productMovements returns product's movements, adding days empty ones the
+// first time it is seen.
+func productMovements(movements map[string][]stripeMovement, product string, days int) []stripeMovement {
+	if movements[product] == nil {
+		movements[product] = make([]stripeMovement, days)
+	}
+	return movements[product]
+}
+
+// trackExpansion records the MRR of every active subscription and adds
+// today's expansion and contraction to movements: the change in each
+// subscription's MRR since the end of the last day it was recorded on.
+// Subscriptions that started paying today count as new instead. A product
+// added to a subscription expands from 0, and one removed from it contracts
+// to 0.
+func (c *StripeClient) trackExpansion(ctx context.Context, subs []stripeSubscription, now time.Time, movements map[string][]stripeMovement) error {
+	today := domain.StartOfDayUTC(now)
+	var (
+		current      []domain.SubscriptionMRR
+		startedToday = make(map[string]bool)
+	)
+	for _, sub := range subs {
+		if sub.Status != "active" {
+			continue
 		}
-		if len(list.Data) == 0 {
-			return mrr, int64(len(subscribers)), fmt.Errorf("stripe: pagination returned empty page")
+		if start, ok := paidFrom(sub, now); ok && !start.Before(today) {
+			startedToday[sub.ID] = true
 		}
-		startingAfter = list.Data[len(list.Data)-1].ID
-		if startingAfter == "" {
-			return mrr, int64(len(subscribers)), fmt.Errorf("stripe: pagination missing last id")
+		for product, mrr := range subscriptionMRR(sub, now) {
+			current = append(current, domain.SubscriptionMRR{Subscription: sub.ID, Product: product, MRR: mrr})
 		}
 	}
 
-	return mrr, int64(len(subscribers)), nil
+	opening, err := c.store.TrackSubscriptionMRR(ctx, today, current)
+	if err != nil {
+		return err
+	}
+	mrrNow := make(map[domain.SubscriptionMRR]int64, len(current))
+	for _, sub := range current {
+		mrrNow[domain.SubscriptionMRR{Subscription: sub.Subscription, Product: sub.Product}] = sub.MRR
+	}
+	for _, open := range opening {
+		if startedToday[open.Subscription] {
+			continue
+		}
+		delta := mrrNow[domain.SubscriptionMRR{Subscription: open.Subscription, Product: open.Product}] - open.MRR
+		if delta == 0 {
+			continue
+		}
+		m := &productMovements(movements, open.Product, stripeMovementDays)[stripeMovementDays-1]
+		if delta > 0 {
+			m.expansion += delta
+		} else {
+			m.contraction -= delta
+		}
+	}
+	return nil
+}
+
+// movementValues returns the daily series for one product's movements, for
+// the stripeMovementDays days ending today. Every day is written, zeros
+// included, so a recomputed day replaces what an earlier refresh stored.
+// Expansion and contraction are only known for today.
+func movementValues(movements []stripeMovement, today time.Time) []domain.DailyValue {
+	if movements == nil {
+		movements = make([]stripeMovement, stripeMovementDays)
+	}
+	values := make([]domain.DailyValue, 0, 4*len(movements)+2)
+	for i, m := range movements {
+		day := today.AddDate(0, 0, i-(len(movements)-1))
+		values = append(values,
+			domain.DailyValue{Day: day, Metric: domain.DailyNewMRR, Value: m.new},
+			domain.DailyValue{Day: day, Metric: domain.DailyChurnedMRR, Value: m.churned},
+			domain.DailyValue{Day: day, Metric: domain.DailyNewSubscriptions, Value: m.newSubs},
+			domain.DailyValue{Day: day, Metric: domain.DailyCanceledSubscriptions, Value: m.canceledSubs},
+		)
+	}
+	last := movements[len(movements)-1]
+	return append(values,
+		domain.DailyValue{Day: today, Metric: domain.DailyExpansionMRR, Value: last.expansion},
+		domain.DailyValue{Day: today, Metric: domain.DailyContractionMRR, Value: last.contraction},
+	)
+}
+
+func (c *StripeClient) Name() string  { return ProviderStripe }
+func (c *StripeClient) Label() string { return "Stripe" }
+
+func (c *StripeClient) Enabled(p domain.Product) bool { return p.StripeID != "" }
+
+// stripeScan is what one refresh's subscription scan yields.
+type stripeScan struct {
+	revenue   map[string]StripeRevenue
+	movements map[string][]stripeMovement
+}
+
+// Begin shares one subscription scan across every product; the first product
+// that needs revenue triggers it and the rest wait for the shared result.
+func (c *StripeClient) Begin(ctx context.Context, _ []domain.Product, w Window) CollectFunc {
+	scan := sync.OnceValues(func() (*stripeScan, error) {
+		first := domain.StartOfDayUTC(w.Now).AddDate(0, 0, -(stripeMovementDays - 1))
+		subs, err := c.listSubscriptionsEndedSince(ctx, first)
+		if err != nil {
+			return nil, err
+		}
+		result := &stripeScan{
+			revenue:   revenueByProduct(subs, w.Now),
+			movements: movementsByProduct(subs, w.Now, stripeMovementDays),
+		}
+		// Without a store there is no earlier MRR to compare against.
+		if c.store != nil {
+			err = c.trackExpansion(ctx, subs, w.Now, result.movements)
+		}
+		return result, err
+	})
+
+	return func(_ context.Context, p domain.Product, s *Sample) error {
+		result, err := scan()
+		if result == nil {
+			return err
+		}
+		s.Metrics.MRR = result.revenue[p.StripeID].MRR
+		s.Metrics.Subscribers = result.revenue[p.StripeID].Subscribers
+		today := domain.StartOfDayUTC(w.Now)
+		s.Daily = append(s.Daily,
+			domain.DailyValue{Day: today, Metric: domain.DailyMRR, Value: s.Metrics.MRR},
+			domain.DailyValue{Day: today, Metric: domain.DailySubscribers, Value: s.Metrics.Subscribers},
+		)
+		s.Daily = append(s.Daily, movementValues(result.movements[p.StripeID], today)...)
+		return err
+	}
 }
@@ -0,0 +1,518 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/store"
+)
+
+// newStripeTestServer serves the given pages of live subscriptions in order,
+// counting those list calls, and canceled as the one page of canceled
+// subscriptions, or none when it is empty.
+func newStripeTestServer(t *testing.T, pages []string, canceled string, calls *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subscriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, _, ok := r.BasicAuth(); !ok || user != "sk_test" {
+			t.Errorf("basic auth user = %q, want sk_test", user)
+		}
+		for _, expand := range []string{"data.discounts", "data.items.data.discounts"} {
+			if !slices.Contains(r.URL.Query()["expand[]"], expand) {
+				t.Errorf("expand[] = %v, missing %s", r.URL.Query()["expand[]"], expand)
+			}
+		}
+		switch status := r.URL.Query().Get("status"); status {
+		case "":
+		case "canceled":
+			if r.URL.Query().Get("current_period_end[gte]") == "" {
+				t.Errorf("canceled listing has no current_period_end[gte] filter")
+			}
+			page := canceled
+			if page == "" {
+				page = `{"has_more": false, "data": []}`
+			}
+			_, _ = w.Write([]byte(page))
+			return
+		default:
+			t.Errorf("status = %q, want live subscriptions or canceled", status)
+		}
+		n := atomic.AddInt32(calls, 1)
+		page := pages[min(int(n)-1, len(pages)-1)]
+		_, _ = w.Write([]byte(page))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStripeGetRevenueByProduct(t *testing.T) {
+	pages := []string{
+		`{"has_more": true, "data": [
+			{"id": "sub_1", "status": "active", "items": {"data": [
+				{"price": {"product": "prod_a", "unit_amount": 1000, "recurring": {"interval": "month"}}, "quantity": 2},
+				{"price": {"product": "prod_b", "unit_amount": 12000, "recurring": {"interval": "year"}}}
+			]}}
+		]}`,
+		`{"has_more": false, "data": [
+			{"id": "sub_2", "status": "active", "items": {"data": [
+				{"price": {"product": "prod_a", "unit_amount": 500, "recurring": {"interval": "month"}}},
+				{"price": {"product": "prod_a", "unit_amount": null}}
+			]}},
+			{"id": "sub_3", "status": "past_due", "items": {"data": [
+				{"price": {"product": "prod_a", "unit_amount": 700, "recurring": {"interval": "month"}}}
+			]}}
+		]}`,
+	}
+
+	var calls int32
+	server := newStripeTestServer(t, pages, "", &calls)
+	client := NewStripeClient("sk_test")
+	client.baseURL = server.URL
+
+	got, err := client.GetRevenueByProduct(context.Background())
+	if err != nil {
+		t.Fatalf("GetRevenueByProduct() error = %v", err)
+	}
+	want := map[string]StripeRevenue{
+		"prod_a": {MRR: 2500, Subscribers: 2},
+		"prod_b": {MRR: 1000, Subscribers: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetRevenueByProduct() = %#v, want %#v", got, want)
+	}
+	if calls != 2 {
+		t.Fatalf("list calls = %d, want 2", calls)
+	}
+}
+
+func TestStripeGetRevenueByProductErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		pages   []string
+		wantErr string
+	}{
+		{
+			name:    "missing key",
+			key:     "",
+			pages:   []string{`{"has_more": false, "data": []}`},
+			wantErr: "secret key is empty",
+		},
+		{
+			name:    "empty page with has_more",
+			key:     "sk_test",
+			pages:   []string{`{"has_more": true, "data": []}`},
+			wantErr: "pagination returned empty page",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := newStripeTestServer(t, tt.pages, "", &calls)
+			client := NewStripeClient(tt.key)
+			client.baseURL = server.URL
+
+			_, err := client.GetRevenueByProduct(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("GetRevenueByProduct() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMonthlyAmount(t *testing.T) {
+	amount := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name string
+		item stripeSubscriptionItem
+		want int64
+	}{
+		{name: "monthly", item: stripeSubscriptionItem{Price: stripePrice{UnitAmount: amount(1000), Recurring: &stripePriceRecurring{Interval: "month"}}}, want: 1000},
+		{name: "yearly", item: stripeSubscriptionItem{Price: stripePrice{UnitAmount: amount(12000), Recurring: &stripePriceRecurring{Interval: "year"}}}, want: 1000},
+		{name: "weekly", item: stripeSubscriptionItem{Price: stripePrice{UnitAmount: amount(300), Recurring: &stripePriceRecurring{Interval: "week"}}}, want: 1300},
+		{name: "every 3 months", item: stripeSubscriptionItem{Price: stripePrice{UnitAmount: amount(3000), Recurring: &stripePriceRecurring{Interval: "month", IntervalCount: 3}}}, want: 1000},
+		{name: "every 2 years", item: stripeSubscriptionItem{Price: stripePrice{UnitAmount: amount(24000), Recurring: &stripePriceRecurring{Interval: "year", IntervalCount: 2}}}, want: 1000},
+		{name: "every 2 weeks", item: stripeSubscriptionItem{Price: stripePrice{UnitAmount: amount(600), Recurring: &stripePriceRecurring{Interval: "week", IntervalCount: 2}}}, want: 1300},
+		{name: "quantity", item: stripeSubscriptionItem{Price: stripePrice{UnitAmount: amount(500)}, Quantity: amount(3)}, want: 1500},
+		{name: "no unit amount", item: stripeSubscriptionItem{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monthlyAmount(tt.item); got != tt.want {
+				t.Errorf("monthlyAmount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionMRR(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) int64 { return now.AddDate(0, 0, -n).Unix() }
+	monthly := &stripePriceRecurring{Interval: "month"}
+	item := func(product string, amount int64, discounts ...stripeDiscount) stripeSubscriptionItem {
+		return stripeSubscriptionItem{Price: stripePrice{Product: product, UnitAmount: &amount, Recurring: monthly}, Discounts: discounts}
+	}
+	percentOff := func(percent float64, duration string, start int64) stripeDiscount {
+		return stripeDiscount{Coupon: stripeCoupon{PercentOff: percent, Duration: duration}, Start: start}
+	}
+	amountOff := func(cents int64, duration string, start int64) stripeDiscount {
+		return stripeDiscount{Coupon: stripeCoupon{AmountOff: cents, Duration: duration}, Start: start}
+	}
+	repeating := func(percent float64, months int64, start int64) stripeDiscount {
+		return stripeDiscount{Coupon: stripeCoupon{PercentOff: percent, Duration: "repeating", DurationInMonths: months}, Start: start}
+	}
+	sub := func(discounts []stripeDiscount, items ...stripeSubscriptionItem) stripeSubscription {
+		return stripeSubscription{Discounts: discounts, Items: stripeSubscriptionItems{Data: items}}
+	}
+	yearly := item("prod_a", 24000)
+	yearly.Price.Recurring = &stripePriceRecurring{Interval: "year"}
+	quarterly := item("prod_a", 6000)
+	quarterly.Price.Recurring = &stripePriceRecurring{Interval: "month", IntervalCount: 3}
+
+	tests := []struct {
+		name string
+		sub  stripeSubscription
+		want map[string]int64
+	}{
+		{
+			name: "no discount",
+			sub:  sub(nil, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 2000},
+		},
+		{
+			name: "percent off forever",
+			sub:  sub([]stripeDiscount{percentOff(50, "forever", daysAgo(400))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 1000},
+		},
+		{
+			name: "amount off forever",
+			sub:  sub([]stripeDiscount{amountOff(500, "forever", daysAgo(400))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 1500},
+		},
+		{
+			name: "amount off never goes below zero",
+			sub:  sub([]stripeDiscount{amountOff(5000, "forever", daysAgo(3))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 0},
+		},
+		{
+			name: "once in the first billing period",
+			sub:  sub([]stripeDiscount{percentOff(50, "once", daysAgo(10))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 1000},
+		},
+		{
+			name: "once after the first billing period",
+			sub:  sub([]stripeDiscount{percentOff(50, "once", daysAgo(40))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 2000},
+		},
+		{
+			name: "once on a yearly price lasts the year",
+			sub:  sub([]stripeDiscount{amountOff(6000, "once", daysAgo(200))}, yearly),
+			want: map[string]int64{"prod_a": 1500},
+		},
+		{
+			name: "amount off every 3 months is spread over them",
+			sub:  sub([]stripeDiscount{amountOff(1500, "forever", daysAgo(400))}, quarterly),
+			want: map[string]int64{"prod_a": 1500},
+		},
+		{
+			name: "repeating within its months",
+			sub:  sub([]stripeDiscount{repeating(25, 3, daysAgo(60))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 1500},
+		},
+		{
+			name: "repeating after its months",
+			sub:  sub([]stripeDiscount{repeating(25, 3, daysAgo(100))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 2000},
+		},
+		{
+			name: "repeating ends when Stripe says",
+			sub: sub([]stripeDiscount{{
+				Coupon: stripeCoupon{PercentOff: 25, Duration: "repeating", DurationInMonths: 12},
+				Start:  daysAgo(60),
+				End:    daysAgo(1),
+			}}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 2000},
+		},
+		{
+			name: "not started yet",
+			sub:  sub([]stripeDiscount{percentOff(50, "forever", now.AddDate(0, 0, 1).Unix())}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 2000},
+		},
+		{
+			name: "discounts stack",
+			sub:  sub([]stripeDiscount{percentOff(50, "forever", daysAgo(5)), amountOff(200, "forever", daysAgo(5))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 800},
+		},
+		{
+			name: "amount off is shared between products",
+			sub:  sub([]stripeDiscount{amountOff(1000, "forever", daysAgo(5))}, item("prod_a", 3000), item("prod_b", 1000)),
+			want: map[string]int64{"prod_a": 2250, "prod_b": 750},
+		},
+		{
+			name: "item discount replaces the subscription's",
+			sub: sub([]stripeDiscount{percentOff(50, "forever", daysAgo(5))},
+				item("prod_a", 2000, percentOff(10, "forever", daysAgo(5))),
+				item("prod_b", 1000)),
+			want: map[string]int64{"prod_a": 1800, "prod_b": 500},
+		},
+		{
+			name: "legacy single discount",
+			sub: stripeSubscription{
+				Discount: &stripeDiscount{Coupon: stripeCoupon{PercentOff: 50, Duration: "forever"}, Start: daysAgo(5)},
+				Items:    stripeSubscriptionItems{Data: []stripeSubscriptionItem{item("prod_a", 2000)}},
+			},
+			want: map[string]int64{"prod_a": 1000},
+		},
+		{
+			name: "fully discounted product is kept",
+			sub:  sub([]stripeDiscount{percentOff(100, "forever", daysAgo(5))}, item("prod_a", 2000)),
+			want: map[string]int64{"prod_a": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subscriptionMRR(tt.sub, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subscriptionMRR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovementsByProduct(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) int64 { return now.AddDate(0, 0, -n).Unix() }
+	item := func(product string, amount int64) stripeSubscriptionItem {
+		return stripeSubscriptionItem{Price: stripePrice{Product: product, UnitAmount: &amount}}
+	}
+	sub := func(status string, start, trialEnd, ended int64, items ...stripeSubscriptionItem) stripeSubscription {
+		return stripeSubscription{Status: status, StartDate: start, TrialEnd: trialEnd, EndedAt: ended, Items: stripeSubscriptionItems{Data: items}}
+	}
+
+	tests := []struct {
+		name string
+		sub  stripeSubscription
+		want map[string]map[int]stripeMovement // days ago
+	}{
+		{
+			name: "started paying",
+			sub:  sub("active", daysAgo(2), 0, 0, item("prod_a", 1000), item("prod_b", 300)),
+			want: map[string]map[int]stripeMovement{
+				"prod_a": {2: {new: 1000, newSubs: 1}},
+				"prod_b": {2: {new: 300, newSubs: 1}},
+			},
+		},
+		{
+			name: "new when the trial ends",
+			sub:  sub("active", daysAgo(20), daysAgo(6), 0, item("prod_a", 1000)),
+			want: map[string]map[int]stripeMovement{"prod_a": {6: {new: 1000, newSubs: 1}}},
+		},
+		{
+			name: "still in trial",
+			sub:  sub("trialing", daysAgo(3), now.AddDate(0, 0, 11).Unix(), 0, item("prod_a", 1000)),
+			want: map[string]map[int]stripeMovement{},
+		},
+		{
+			name: "canceled during trial",
+			sub:  sub("canceled", daysAgo(10), daysAgo(1), daysAgo(4), item("prod_a", 1000)),
+			want: map[string]map[int]stripeMovement{},
+		},
+		{
+			name: "started and churned in the window",
+			sub:  sub("canceled", daysAgo(8), 0, daysAgo(1), item("prod_a", 1000)),
+			want: map[string]map[int]stripeMovement{"prod_a": {8: {new: 1000, newSubs: 1}, 1: {churned: 1000, canceledSubs: 1}}},
+		},
+		{
+			name: "churned after starting before the window",
+			sub:  sub("canceled", daysAgo(400), 0, daysAgo(0), item("prod_a", 1000)),
+			want: map[string]map[int]stripeMovement{"prod_a": {0: {churned: 1000, canceledSubs: 1}}},
+		},
+		{
+			name: "discount it started and ended with",
+			sub: stripeSubscription{
+				Status:    "canceled",
+				StartDate: daysAgo(8),
+				EndedAt:   daysAgo(1),
+				Discounts: []stripeDiscount{{Coupon: stripeCoupon{PercentOff: 50, Duration: "once"}, Start: daysAgo(8)}},
+				Items:     stripeSubscriptionItems{Data: []stripeSubscriptionItem{item("prod_a", 1000)}},
+			},
+			want: map[string]map[int]stripeMovement{"prod_a": {8: {new: 500, newSubs: 1}, 1: {churned: 500, canceledSubs: 1}}},
+		},
+		{
+			name: "past due isn't new",
+			sub:  sub("past_due", daysAgo(3), 0, 0, item("prod_a", 1000)),
+			want: map[string]map[int]stripeMovement{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := movementsByProduct([]stripeSubscription{tt.sub}, now, stripeMovementDays)
+			want := make(map[string][]stripeMovement, len(tt.want))
+			for product, days := range tt.want {
+				want[product] = make([]stripeMovement, stripeMovementDays)
+				for ago, m := range days {
+					want[product][stripeMovementDays-1-ago] = m
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("movementsByProduct() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestStripeBeginTracksMovement(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	day1 := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	page := func(amounts ...int64) string {
+		subs := make([]string, 0, len(amounts))
+		for i, amount := range amounts {
+			subs = append(subs, fmt.Sprintf(`{"id": "sub_%d", "status": "active", "start_date": %d, "items": {"data": [{"price": {"product": "prod_a", "unit_amount": %d}}]}}`,
+				i+1, day1.AddDate(-1, 0, 0).Unix(), amount))
+		}
+		return `{"has_more": false, "data": [` + strings.Join(subs, ",") + `]}`
+	}
+	collect := func(t *testing.T, at time.Time, listing string) map[string]int64 {
+		t.Helper()
+		var calls int32
+		server := newStripeTestServer(t, []string{listing}, "", &calls)
+		client := NewStripeClient("sk_test").withStore(s)
+		client.baseURL = server.URL
+
+		sample := &Sample{Metrics: &domain.Metrics{}}
+		if err := client.Begin(context.Background(), nil, Window{Now: at})(context.Background(), domain.Product{StripeID: "prod_a"}, sample); err != nil {
+			t.Fatalf("collect() error = %v", err)
+		}
+		today := make(map[string]int64)
+		for _, v := range sample.Daily {
+			if v.Day.Equal(domain.StartOfDayUTC(at)) {
+				today[v.Metric] = v.Value
+			}
+		}
+		return today
+	}
+
+	collect(t, day1, page(1000, 2000, 500))
+	collect(t, day2, page(1500, 2000, 200)) // an earlier refresh the same day
+	got := collect(t, day2.Add(time.Hour), page(1500, 1200, 200))
+
+	want := map[string]int64{
+		domain.DailyMRR:                   2900,
+		domain.DailySubscribers:           3,
+		domain.DailyNewMRR:                0,
+		domain.DailyExpansionMRR:          500,
+		domain.DailyContractionMRR:        1100,
+		domain.DailyChurnedMRR:            0,
+		domain.DailyNewSubscriptions:      0,
+		domain.DailyCanceledSubscriptions: 0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("today's values = %v, want %v", got, want)
+	}
+}
+
+func TestStripeBeginTracksProductChanges(t *testing.T) {
+	s, err := store.Open(":memory:")
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	day1 := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	listing := func(product string) string {
+		return fmt.Sprintf(`{"has_more": false, "data": [{"id": "sub_1", "status": "active", "start_date": %d, "items": {"data": [{"price": {"product": %q, "unit_amount": 1000}}]}}]}`,
+			day1.AddDate(-1, 0, 0).Unix(), product)
+	}
+	collect := func(t *testing.T, at time.Time, product string) map[string]map[string]int64 {
+		t.Helper()
+		var calls int32
+		server := newStripeTestServer(t, []string{listing(product)}, "", &calls)
+		client := NewStripeClient("sk_test").withStore(s)
+		client.baseURL = server.URL
+
+		collect := client.Begin(context.Background(), nil, Window{Now: at})
+		moved := make(map[string]map[string]int64)
+		for _, id := range []string{"prod_a", "prod_b"} {
+			sample := &Sample{Metrics: &domain.Metrics{}}
+			if err := collect(context.Background(), domain.Product{StripeID: id}, sample); err != nil {
+				t.Fatalf("collect() error = %v", err)
+			}
+			moved[id] = make(map[string]int64)
+			for _, v := range sample.Daily {
+				if v.Day.Equal(domain.StartOfDayUTC(at)) && (v.Metric == domain.DailyExpansionMRR || v.Metric == domain.DailyContractionMRR) {
+					moved[id][v.Metric] = v.Value
+				}
+			}
+		}
+		return moved
+	}
+
+	collect(t, day1, "prod_a")
+	got := collect(t, day1.AddDate(0, 0, 1), "prod_b")
+
+	// Moving the subscription from prod_a to prod_b contracts one and
+	// expands the other rather than going unnoticed.
+	want := map[string]map[string]int64{
+		"prod_a": {domain.DailyExpansionMRR: 0, domain.DailyContractionMRR: 1000},
+		"prod_b": {domain.DailyExpansionMRR: 1000, domain.DailyContractionMRR: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("today's movements = %v, want %v", got, want)
+	}
+}
+
+func TestStripeBeginListsRecentlyCanceled(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	live := fmt.Sprintf(`{"has_more": false, "data": [
+		{"id": "sub_1", "status": "active", "start_date": %d, "items": {"data": [{"price": {"product": "prod_a", "unit_amount": 1000}}]}}
+	]}`, now.AddDate(-1, 0, 0).Unix())
+	canceled := fmt.Sprintf(`{"has_more": false, "data": [
+		{"id": "sub_2", "status": "canceled", "start_date": %d, "ended_at": %d, "items": {"data": [{"price": {"product": "prod_a", "unit_amount": 400}}]}}
+	]}`, now.AddDate(-1, 0, 0).Unix(), yesterday.Unix())
+
+	var calls int32
+	server := newStripeTestServer(t, []string{live}, canceled, &calls)
+	client := NewStripeClient("sk_test")
+	client.baseURL = server.URL
+
+	sample := &Sample{Metrics: &domain.Metrics{}}
+	if err := client.Begin(context.Background(), nil, Window{Now: now})(context.Background(), domain.Product{StripeID: "prod_a"}, sample); err != nil {
+		t.Fatalf("collect() error = %v", err)
+	}
+	if sample.Metrics.MRR != 1000 || sample.Metrics.Subscribers != 1 {
+		t.Errorf("MRR, Subscribers = %d, %d, want 1000, 1", sample.Metrics.MRR, sample.Metrics.Subscribers)
+	}
+	var churned int64
+	for _, v := range sample.Daily {
+		if v.Metric == domain.DailyChurnedMRR && v.Day.Equal(domain.StartOfDayUTC(yesterday)) {
+			churned = v.Value
+		}
+	}
+	if churned != 400 {
+		t.Errorf("yesterday's churned MRR = %d, want 400", churned)
+	}
+}
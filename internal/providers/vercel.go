@@ -0,0 +1,174 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+const vercelBaseURL = "https://api.vercel.com"
+
+// vercelMaxPages bounds pagination when counting recent failed deploys.
+const vercelMaxPages = 10
+
+type VercelClient struct {
+	token      string
+	teamID     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewVercelClient(token, teamID string) *VercelClient {
+	return &VercelClient{
+		token:      token,
+		teamID:     teamID,
+		baseURL:    vercelBaseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type vercelDeploymentList struct {
+	Deployments []vercelDeployment `json:"deployments"`
+	Pagination  struct {
+		Next *int64 `json:"next"`
+	} `json:"pagination"`
+}
+
+type vercelDeployment struct {
+	UID        string `json:"uid"`
+	State      string `json:"state"`
+	ReadyState string `json:"readyState"`
+	Created    int64  `json:"created"`    // unix ms
+	BuildingAt int64  `json:"buildingAt"` // unix ms
+	Ready      int64  `json:"ready"`      // unix ms
+}
+
+func (d vercelDeployment) state() string {
+	if d.State != "" {
+		return d.State
+	}
+	return d.ReadyState
+}
+
+type VercelDeployStatus struct {
+	State         string    // latest production deployment state, e.g. "READY", "ERROR"
+	DeployedAt    time.Time // when the latest production deployment was created
+	BuildDuration int64     // seconds; 0 while building or unknown
+	FailedDeploys int64     // production deploys in ERROR state since `since`
+}
+
+// GetDeployStatus returns the latest production deployment and the number of
+// failed production deployments created since `since`.
+func (c *VercelClient) GetDeployStatus(ctx context.Context, projectID string, since time.Time) (*VercelDeployStatus, error) {
+	if c.token == "" {
+		return nil, fmt.Errorf("vercel: token is empty")
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("vercel: project id is empty")
+	}
+
+	var (
+		status VercelDeployStatus
+		until  int64
+		seen   bool
+	)
+	sinceMs := since.UnixMilli()
+
+	for page := 0; page < vercelMaxPages; page++ {
+		list, err := c.listDeployments(ctx, projectID, until)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range list.Deployments {
+			if !seen {
+				seen = true
+				status.State = d.state()
+				status.DeployedAt = time.UnixMilli(d.Created)
+				if d.Ready > 0 && d.BuildingAt > 0 && d.Ready >= d.BuildingAt {
+					status.BuildDuration = (d.Ready - d.BuildingAt) / 1000
+				}
+			}
+			if d.Created >= sinceMs && d.state() == "ERROR" {
+				status.FailedDeploys++
+			}
+		}
+
+		// Deployments are newest first; stop once the page reaches past the window.
+		if len(list.Deployments) == 0 || list.Pagination.Next == nil {
+			break
+		}
+		if list.Deployments[len(list.Deployments)-1].Created < sinceMs {
+			break
+		}
+		until = *list.Pagination.Next
+	}
+
+	return &status, nil
+}
+
+func (c *VercelClient) listDeployments(ctx context.Context, projectID string, until int64) (*vercelDeploymentList, error) {
+	params := url.Values{}
+	params.Set("projectId", projectID)
+	params.Set("target", "production")
+	params.Set("limit", "100")
+	if c.teamID != "" {
+		params.Set("teamId", c.teamID)
+	}
+	if until > 0 {
+		params.Set("until", strconv.FormatInt(until, 10))
+	}
+
+	endpoint := c.baseURL + "/v6/deployments?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vercel: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vercel: list deployments: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return nil, fmt.Errorf("vercel: list deployments: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var list vercelDeploymentList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("vercel: decode deployments: %w", err)
+	}
+	return &list, nil
+}
+
+func (c *VercelClient) Name() string  { return ProviderVercel }
+func (c *VercelClient) Label() string { return "Vercel" }
+
+func (c *VercelClient) Enabled(p domain.Product) bool { return p.VercelProjectID != "" }
+
+func (c *VercelClient) Begin(_ context.Context, _ []domain.Product, w Window) CollectFunc {
+	return func(ctx context.Context, p domain.Product, s *Sample) error {
+		deploy, err := c.GetDeployStatus(ctx, p.VercelProjectID, w.WeekAgo)
+		if err != nil {
+			return err
+		}
+		s.Metrics.DeployState = deploy.State
+		s.Metrics.DeployedAt = deploy.DeployedAt
+		s.Metrics.BuildDuration = deploy.BuildDuration
+		s.Metrics.FailedDeploys = deploy.FailedDeploys
+		return nil
+	}
+}
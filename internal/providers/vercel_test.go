@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVercelGetDeployStatus(t *testing.T) {
+	now := time.Now()
+	ms := func(d time.Duration) int64 { return now.Add(-d).UnixMilli() }
+
+	tests := []struct {
+		name  string
+		pages []map[string]any
+		want  VercelDeployStatus
+	}{
+		{
+			name: "latest ready with failures in window",
+			pages: []map[string]any{{
+				"deployments": []map[string]any{
+					{"uid": "d1", "state": "READY", "created": ms(time.Hour), "buildingAt": ms(time.Hour), "ready": ms(time.Hour) + 95_000},
+					{"uid": "d2", "state": "ERROR", "created": ms(48 * time.Hour)},
+					{"uid": "d3", "state": "ERROR", "created": ms(10 * 24 * time.Hour)},
+				},
+				"pagination": map[string]any{"next": nil},
+			}},
+			want: VercelDeployStatus{State: "READY", DeployedAt: time.UnixMilli(ms(time.Hour)), BuildDuration: 95, FailedDeploys: 1},
+		},
+		{
+			name: "latest failed uses readyState and paginates",
+			pages: []map[string]any{
+				{
+					"deployments": []map[string]any{
+						{"uid": "d1", "readyState": "ERROR", "created": ms(time.Hour)},
+					},
+					"pagination": map[string]any{"next": ms(time.Hour) - 1},
+				},
+				{
+					"deployments": []map[string]any{
+						{"uid": "d2", "state": "ERROR", "created": ms(2 * time.Hour)},
+					},
+					"pagination": map[string]any{"next": nil},
+				},
+			},
+			want: VercelDeployStatus{State: "ERROR", DeployedAt: time.UnixMilli(ms(time.Hour)), FailedDeploys: 2},
+		},
+		{
+			name:  "no deployments",
+			pages: []map[string]any{{"deployments": []map[string]any{}}},
+			want:  VercelDeployStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+					t.Errorf("Authorization = %q, want bearer token", got)
+				}
+				q := r.URL.Query()
+				if q.Get("projectId") != "prj_1" || q.Get("target") != "production" || q.Get("teamId") != "team_1" {
+					t.Errorf("unexpected query %q", r.URL.RawQuery)
+				}
+				if calls > 0 && q.Get("until") == "" {
+					t.Errorf("page %d missing until", calls)
+				}
+				page := tt.pages[min(calls, len(tt.pages)-1)]
+				calls++
+				_ = json.NewEncoder(w).Encode(page)
+			}))
+			defer server.Close()
+
+			client := NewVercelClient("tok", "team_1")
+			client.baseURL = server.URL
+
+			got, err := client.GetDeployStatus(context.Background(), "prj_1", now.AddDate(0, 0, -7))
+			if err != nil {
+				t.Fatalf("GetDeployStatus() error = %v", err)
+			}
+			if got.State != tt.want.State || !got.DeployedAt.Equal(tt.want.DeployedAt) ||
+				got.BuildDuration != tt.want.BuildDuration || got.FailedDeploys != tt.want.FailedDeploys {
+				t.Fatalf("GetDeployStatus() = %+v, want %+v", *got, tt.want)
+			}
+			if calls != len(tt.pages) {
+				t.Fatalf("calls = %d, want %d", calls, len(tt.pages))
+			}
+		})
+	}
+}
+
+func TestVercelGetDeployStatusErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"code":"forbidden"}}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewVercelClient("tok", "")
+	client.baseURL = server.URL
+	if _, err := client.GetDeployStatus(context.Background(), "prj_1", time.Now()); err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Fatalf("GetDeployStatus() error = %v, want status 403", err)
+	}
+
+	if _, err := NewVercelClient("", "").GetDeployStatus(context.Background(), "prj_1", time.Now()); err == nil {
+		t.Fatalf("GetDeployStatus() without token error = nil, want error")
+	}
+}
@@ -0,0 +1,119 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// IncidentRow is the serializable view of a domain.Incident.
+type IncidentRow struct {
+	Name      string     `json:"name"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"` // null while ongoing
+	Duration  int64      `json:"duration_s"`
+	Status    string     `json:"status"`
+	Reason    string     `json:"reason"`
+}
+
+// IncidentRows builds incident rows in the given order. Ongoing incidents
+// last until now.
+func IncidentRows(incidents []domain.Incident, now time.Time) []IncidentRow {
+	rows := make([]IncidentRow, 0, len(incidents))
+	for _, i := range incidents {
+		row := IncidentRow{
+			Name:      i.ProductName,
+			StartedAt: i.StartedAt,
+			Duration:  int64(i.Duration(now).Seconds()),
+			Status:    i.Status,
+			Reason:    i.Reason,
+		}
+		if !i.Ongoing() {
+			endedAt := i.EndedAt
+			row.EndedAt = &endedAt
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// FormatDuration renders an incident duration to the minute, e.g. "2h5m" or
+// "1h", or in seconds when it is shorter than a minute.
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	s := strings.TrimSuffix(d.Truncate(time.Minute).String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// WriteIncidents renders incident rows to w in the requested format.
+func WriteIncidents(w io.Writer, format Format, rows []IncidentRow) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, rows)
+	case FormatCSV:
+		return writeIncidentsCSV(w, rows)
+	case FormatTable, "":
+		return writeIncidentsTable(w, rows)
+	default:
+		return fmt.Errorf("report: unknown format %q", format)
+	}
+}
+
+func writeIncidentsCSV(w io.Writer, rows []IncidentRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "started_at", "ended_at", "duration_s", "status", "reason"}); err != nil {
+		return fmt.Errorf("report: write csv: %w", err)
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Name,
+			formatTimestamp(row.StartedAt),
+			formatTimePtr(row.EndedAt),
+			strconv.FormatInt(row.Duration, 10),
+			row.Status,
+			row.Reason,
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("report: write csv: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("report: write csv: %w", err)
+	}
+	return nil
+}
+
+func writeIncidentsTable(w io.Writer, rows []IncidentRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tSTARTED\tENDED\tDURATION\tSTATUS\tREASON")
+	for _, row := range rows {
+		ended := "ongoing"
+		if row.EndedAt != nil {
+			ended = row.EndedAt.Local().Format("2006-01-02 15:04")
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Name,
+			row.StartedAt.Local().Format("2006-01-02 15:04"),
+			ended,
+			FormatDuration(time.Duration(row.Duration)*time.Second),
+			row.Status,
+			row.Reason,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("report: write table: %w", err)
+	}
+	return nil
+}
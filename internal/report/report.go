@@ -0,0 +1,273 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
+// ParseFormat validates a user-supplied output format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatTable, FormatJSON, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("report: unknown format %q (want table, json or csv)", s)
+	}
+}
+
+// Row is the flattened, serializable view of one product's metrics.
+type Row struct {
+	Name             string             `json:"name"`
+	Domain           string             `json:"domain"`
+	Timestamp        time.Time          `json:"timestamp"`
+	Visits           int64              `json:"visits"`
+	Uniques          int64              `json:"uniques"`
+	MRR              int64              `json:"mrr_cents"`
+	Subscribers      int64              `json:"subscribers"`
+	UnresolvedIssues int64              `json:"unresolved_issues"`
+	ErrorEvents      int64              `json:"error_events_24h"`
+	NewIssues        int64              `json:"new_issues_7d"`
+	DeployState      string             `json:"deploy_state"`
+	DeployedAt       *time.Time         `json:"deployed_at"`
+	BuildDuration    int64              `json:"build_duration_s"`
+	FailedDeploys    int64              `json:"failed_deploys_7d"`
+	Commits          int64              `json:"commits_7d"`
+	OpenIssues       int64              `json:"open_issues"`
+	OpenPRs          int64              `json:"open_prs"`
+	Stars            int64              `json:"stars"`
+	CIStatus         string             `json:"ci_status"`
+	HealthStatus     string             `json:"health_status"`
+	ResponseTime     int64              `json:"response_time_ms"`
+	HealthReason     string             `json:"health_reason,omitempty"`     // JSON only
+	DNSTime          int64              `json:"dns_ms,omitempty"`            // JSON only
+	ConnectTime      int64              `json:"connect_ms,omitempty"`        // JSON only
+	TLSTime          int64              `json:"tls_ms,omitempty"`            // JSON only
+	TTFB             int64              `json:"ttfb_ms,omitempty"`           // JSON only
+	DownloadTime     int64              `json:"download_ms,omitempty"`       // JSON only
+	CertExpiresAt    *time.Time         `json:"cert_expires_at,omitempty"`   // JSON only
+	CertDaysLeft     *int               `json:"cert_days_left,omitempty"`    // JSON only
+	CertIssuer       string             `json:"cert_issuer,omitempty"`       // JSON only
+	CertError        string             `json:"cert_error,omitempty"`        // JSON only
+	DomainExpiresAt  *time.Time         `json:"domain_expires_at,omitempty"` // JSON only
+	DomainDaysLeft   *int               `json:"domain_days_left,omitempty"`  // JSON only
+	DomainRegistrar  string             `json:"domain_registrar,omitempty"`  // JSON only
+	Signal           string             `json:"signal"`
+	Warnings         []string           `json:"warnings"`
+	Errors           []string           `json:"errors"`
+	Values           map[string]float64 `json:"values,omitempty"` // registered providers; JSON only
+	Labels           map[string]string  `json:"labels,omitempty"` // registered providers; JSON only
+}
+
+// Rows builds report rows in product order. Products without metrics are
+// included with zero values so scripts always see every configured product.
+func Rows(products []domain.Product, metrics map[string]*domain.Metrics) []Row {
+	rows := make([]Row, 0, len(products))
+	for _, p := range products {
+		row := Row{
+			Name:     p.Name,
+			Domain:   p.Domain,
+			Warnings: []string{},
+			Errors:   []string{},
+		}
+		if m := metrics[p.Name]; m != nil {
+			row.Timestamp = m.Timestamp
+			row.Visits = m.Visits
+			row.Uniques = m.Uniques
+			row.MRR = m.MRR
+			row.Subscribers = m.Subscribers
+			row.UnresolvedIssues = m.UnresolvedIssues
+			row.ErrorEvents = m.ErrorEvents
+			row.NewIssues = m.NewIssues
+			row.DeployState = m.DeployState
+			if !m.DeployedAt.IsZero() {
+				deployedAt := m.DeployedAt
+				row.DeployedAt = &deployedAt
+			}
+			row.BuildDuration = m.BuildDuration
+			row.FailedDeploys = m.FailedDeploys
+			row.Commits = m.Commits
+			row.OpenIssues = m.OpenIssues
+			row.OpenPRs = m.OpenPRs
+			row.Stars = m.Stars
+			row.CIStatus = m.CIStatus
+			row.HealthStatus = m.HealthStatus
+			row.ResponseTime = m.ResponseTime
+			row.HealthReason = m.HealthReason
+			row.DNSTime = m.DNSTime
+			row.ConnectTime = m.ConnectTime
+			row.TLSTime = m.TLSTime
+			row.TTFB = m.TTFB
+			row.DownloadTime = m.DownloadTime
+			if !m.CertExpiresAt.IsZero() {
+				expiresAt, daysLeft := m.CertExpiresAt, m.CertDaysLeft()
+				row.CertExpiresAt, row.CertDaysLeft = &expiresAt, &daysLeft
+			}
+			row.CertIssuer = m.CertIssuer
+			row.CertError = m.CertError
+			if expiresAt := m.DomainExpiresAt(); !expiresAt.IsZero() {
+				daysLeft := m.DomainDaysLeft()
+				row.DomainExpiresAt, row.DomainDaysLeft = &expiresAt, &daysLeft
+			}
+			row.DomainRegistrar = m.DomainRegistrar()
+			row.Values = m.Values
+			row.Labels = m.Labels
+			row.Signal = string(m.ComputeSignal())
+			for _, w := range m.Warnings() {
+				row.Warnings = append(row.Warnings, string(w))
+			}
+			row.Errors = append(row.Errors, m.Errors...)
+		} else {
+			row.Errors = append(row.Errors, "no metrics returned")
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// HasErrors reports whether any row carries fetch errors.
+func HasErrors(rows []Row) bool {
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Write renders rows to w in the requested format.
+func Write(w io.Writer, format Format, rows []Row) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, rows)
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatTable, "":
+		return writeTable(w, rows)
+	default:
+		return fmt.Errorf("report: unknown format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("report: encode json: %w", err)
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	header := []string{"name", "domain", "timestamp", "visits", "uniques", "mrr_cents", "subscribers", "unresolved_issues", "error_events_24h", "new_issues_7d", "deploy_state", "deployed_at", "build_duration_s", "failed_deploys_7d", "commits_7d", "open_issues", "open_prs", "stars", "ci_status", "health_status", "response_time_ms", "signal", "warnings", "errors"}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("report: write csv: %w", err)
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Name,
+			row.Domain,
+			formatTimestamp(row.Timestamp),
+			strconv.FormatInt(row.Visits, 10),
+			strconv.FormatInt(row.Uniques, 10),
+			strconv.FormatInt(row.MRR, 10),
+			strconv.FormatInt(row.Subscribers, 10),
+			strconv.FormatInt(row.UnresolvedIssues, 10),
+			strconv.FormatInt(row.ErrorEvents, 10),
+			strconv.FormatInt(row.NewIssues, 10),
+			row.DeployState,
+			formatTimePtr(row.DeployedAt),
+			strconv.FormatInt(row.BuildDuration, 10),
+			strconv.FormatInt(row.FailedDeploys, 10),
+			strconv.FormatInt(row.Commits, 10),
+			strconv.FormatInt(row.OpenIssues, 10),
+			strconv.FormatInt(row.OpenPRs, 10),
+			strconv.FormatInt(row.Stars, 10),
+			row.CIStatus,
+			row.HealthStatus,
+			strconv.FormatInt(row.ResponseTime, 10),
+			row.Signal,
+			strings.Join(row.Warnings, "; "),
+			strings.Join(row.Errors, "; "),
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("report: write csv: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("report: write csv: %w", err)
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, rows []Row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tDOMAIN\tVISITS\tUNIQUES\tMRR\tSUBS\tISSUES\tDEPLOY\tCOMMITS\tHEALTH\tLATENCY\tERRORS")
+	for _, row := range rows {
+		health := row.HealthStatus
+		if health == "" {
+			health = "-"
+		}
+		deploy := strings.ToLower(row.DeployState)
+		if deploy == "" {
+			deploy = "-"
+		}
+		latency := "n/a"
+		if row.ResponseTime > 0 {
+			latency = fmt.Sprintf("%dms", row.ResponseTime)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%d\t%d\t%s\t%d\t%s\t%s\t%d\n",
+			row.Name,
+			row.Domain,
+			row.Visits,
+			row.Uniques,
+			formatCurrency(row.MRR),
+			row.Subscribers,
+			row.UnresolvedIssues,
+			deploy,
+			row.Commits,
+			health,
+			latency,
+			len(row.Errors),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("report: write table: %w", err)
+	}
+	return nil
+}
+
+func formatTimestamp(ts time.Time) string {
+	if ts.IsZero() {
+		return ""
+	}
+	return ts.UTC().Format(time.RFC3339)
+}
+
+func formatTimePtr(ts *time.Time) string {
+	if ts == nil {
+		return ""
+	}
+	return formatTimestamp(*ts)
+}
+
+func formatCurrency(cents int64) string {
+	return fmt.Sprintf("$%.2f", float64(cents)/100)
+}
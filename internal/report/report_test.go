@@ -0,0 +1,207 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{name: "table", input: "table", want: FormatTable},
+		{name: "json uppercase", input: "JSON", want: FormatJSON},
+		{name: "csv padded", input: " csv ", want: FormatCSV},
+		{name: "unknown", input: "yaml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseFormat(%q) error = nil, want error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFormat(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func testRows() []Row {
+	products := []domain.Product{
+		{Name: "App", Domain: "app.com"},
+		{Name: "Tool", Domain: "tool.com"},
+		{Name: "Missing", Domain: "missing.com"},
+	}
+	metrics := map[string]*domain.Metrics{
+		"App": {
+			ProductName:  "App",
+			Timestamp:    time.Unix(100, 0),
+			Visits:       150,
+			Uniques:      90,
+			MRR:          1299,
+			Subscribers:  3,
+			HealthStatus: "healthy",
+			ResponseTime: 120,
+		},
+		"Tool": {
+			ProductName: "Tool",
+			Timestamp:   time.Unix(100, 0),
+			Errors:      []string{"PostHog: boom"},
+		},
+	}
+	return Rows(products, metrics)
+}
+
+func TestRows(t *testing.T) {
+	rows := testRows()
+
+	if len(rows) != 3 {
+		t.Fatalf("Rows() len = %d, want 3", len(rows))
+	}
+	if rows[0].Signal != string(domain.SignalTraction) {
+		t.Errorf("rows[0].Signal = %q, want %q", rows[0].Signal, domain.SignalTraction)
+	}
+	if !reflect.DeepEqual(rows[1].Errors, []string{"PostHog: boom"}) {
+		t.Errorf("rows[1].Errors = %v, want PostHog error", rows[1].Errors)
+	}
+	if len(rows[2].Errors) != 1 {
+		t.Errorf("rows[2].Errors = %v, want missing metrics error", rows[2].Errors)
+	}
+	if !HasErrors(rows) {
+		t.Errorf("HasErrors() = false, want true")
+	}
+	if HasErrors(rows[:1]) {
+		t.Errorf("HasErrors(rows[:1]) = true, want false")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rows := testRows()
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatJSON, rows); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		var got []map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal json: %v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("json rows = %d, want 3", len(got))
+		}
+		if got[0]["mrr_cents"] != float64(1299) {
+			t.Errorf("mrr_cents = %v, want 1299", got[0]["mrr_cents"])
+		}
+		if errs, ok := got[0]["errors"].([]any); !ok || len(errs) != 0 {
+			t.Errorf("errors = %#v, want empty array", got[0]["errors"])
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatCSV, rows); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("read csv: %v", err)
+		}
+		if len(records) != 4 {
+			t.Fatalf("csv records = %d, want 4", len(records))
+		}
+		if records[0][0] != "name" {
+			t.Errorf("csv header[0] = %q, want name", records[0][0])
+		}
+		want := []string{"App", "app.com", "1970-01-01T00:01:40Z", "150", "90", "1299", "3", "0", "0", "0", "", "", "0", "0", "0", "0", "0", "0", "", "healthy", "120", "traction", "", ""}
+		if !reflect.DeepEqual(records[1], want) {
+			t.Errorf("csv row = %v, want %v", records[1], want)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, FormatTable, rows); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("table lines = %d, want 4", len(lines))
+		}
+		if !strings.Contains(lines[1], "$12.99") || !strings.Contains(lines[1], "120ms") {
+			t.Errorf("table row = %q, want currency and latency", lines[1])
+		}
+	})
+}
+
+func TestWriteIncidents(t *testing.T) {
+	now := time.Unix(10000, 0)
+	rows := IncidentRows([]domain.Incident{
+		{ProductName: "App", StartedAt: time.Unix(9000, 0), Status: "degraded", Reason: "slow: 2.1s"},
+		{ProductName: "Api", StartedAt: time.Unix(100, 0), EndedAt: time.Unix(7600, 0), Status: "down", Reason: "status 503"},
+	}, now)
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteIncidents(&buf, FormatJSON, rows); err != nil {
+			t.Fatalf("WriteIncidents() error = %v", err)
+		}
+		var got []map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("unmarshal json: %v", err)
+		}
+		if len(got) != 2 || got[0]["ended_at"] != nil || got[0]["duration_s"] != float64(1000) {
+			t.Errorf("json = %v, want an ongoing incident of 1000s first", got)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteIncidents(&buf, FormatCSV, rows); err != nil {
+			t.Fatalf("WriteIncidents() error = %v", err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("read csv: %v", err)
+		}
+		want := []string{"Api", "1970-01-01T00:01:40Z", "1970-01-01T02:06:40Z", "7500", "down", "status 503"}
+		if len(records) != 3 || !reflect.DeepEqual(records[2], want) {
+			t.Errorf("csv = %v, want header and 2 rows ending %v", records, want)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteIncidents(&buf, FormatTable, rows); err != nil {
+			t.Fatalf("WriteIncidents() error = %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("table lines = %d, want 3", len(lines))
+		}
+		if !strings.Contains(lines[1], "ongoing") || !strings.Contains(lines[1], "16m") {
+			t.Errorf("table row = %q, want ongoing for 16m", lines[1])
+		}
+		if !strings.Contains(lines[2], "2h5m") || !strings.Contains(lines[2], "status 503") {
+			t.Errorf("table row = %q, want 2h5m and the reason", lines[2])
+		}
+	})
+}
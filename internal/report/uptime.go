@@ -0,0 +1,160 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// UptimeRow is one product's uptime over each reported window.
+type UptimeRow struct {
+	Name    string         `json:"name"`
+	Domain  string         `json:"domain"`
+	Windows []UptimeWindow `json:"windows"`
+}
+
+// UptimeWindow is the serializable view of a domain.Uptime. Durations are
+// whole seconds.
+type UptimeWindow struct {
+	Window    string   `json:"window"`         // e.g. "24h", "7d"
+	Percent   *float64 `json:"uptime_percent"` // null when nothing was monitored
+	Monitored int64    `json:"monitored_s"`
+	Degraded  int64    `json:"degraded_s"`
+	Down      int64    `json:"down_s"`
+}
+
+// UptimeRows builds uptime rows in product order. Products without uptime get
+// no windows.
+func UptimeRows(products []domain.Product, uptime map[string][]domain.Uptime) []UptimeRow {
+	rows := make([]UptimeRow, 0, len(products))
+	for _, p := range products {
+		row := UptimeRow{Name: p.Name, Domain: p.Domain, Windows: []UptimeWindow{}}
+		for _, u := range uptime[p.Name] {
+			window := UptimeWindow{
+				Window:    FormatWindow(u.Window),
+				Monitored: int64(u.Monitored().Seconds()),
+				Degraded:  int64(u.Degraded.Seconds()),
+				Down:      int64(u.Down.Seconds()),
+			}
+			if percent, ok := u.Percent(); ok {
+				window.Percent = &percent
+			}
+			row.Windows = append(row.Windows, window)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// FormatWindow renders a report window: hours under two days, else days.
+func FormatWindow(d time.Duration) string {
+	if d >= 48*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%dh", int64(d.Hours()))
+}
+
+// FormatUptime renders an uptime percentage, or "-" when nothing was
+// monitored.
+func FormatUptime(percent *float64) string {
+	if percent == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*percent, 'f', 2, 64) + "%"
+}
+
+// WriteUptime renders uptime rows to w in the requested format. CSV has one
+// record per product and window.
+func WriteUptime(w io.Writer, format Format, rows []UptimeRow) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, rows)
+	case FormatCSV:
+		return writeUptimeCSV(w, rows)
+	case FormatTable, "":
+		return writeUptimeTable(w, rows)
+	default:
+		return fmt.Errorf("report: unknown format %q", format)
+	}
+}
+
+func writeUptimeCSV(w io.Writer, rows []UptimeRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "domain", "window", "uptime_percent", "monitored_s", "degraded_s", "down_s"}); err != nil {
+		return fmt.Errorf("report: write csv: %w", err)
+	}
+	for _, row := range rows {
+		for _, window := range row.Windows {
+			percent := ""
+			if window.Percent != nil {
+				percent = strconv.FormatFloat(*window.Percent, 'f', -1, 64)
+			}
+			record := []string{
+				row.Name,
+				row.Domain,
+				window.Window,
+				percent,
+				strconv.FormatInt(window.Monitored, 10),
+				strconv.FormatInt(window.Degraded, 10),
+				strconv.FormatInt(window.Down, 10),
+			}
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("report: write csv: %w", err)
+			}
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("report: write csv: %w", err)
+	}
+	return nil
+}
+
+// writeUptimeTable prints one column per window, then the downtime in the
+// longest window.
+func writeUptimeTable(w io.Writer, rows []UptimeRow) error {
+	var windows []string
+	for _, row := range rows {
+		if len(row.Windows) > len(windows) {
+			windows = windows[:0]
+			for _, window := range row.Windows {
+				windows = append(windows, strings.ToUpper(window.Window))
+			}
+		}
+	}
+	header := append([]string{"NAME", "DOMAIN"}, windows...)
+	if len(windows) > 0 {
+		header = append(header, "DOWN "+windows[len(windows)-1])
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := []string{row.Name, row.Domain}
+		for i := range windows {
+			if i < len(row.Windows) {
+				cells = append(cells, FormatUptime(row.Windows[i].Percent))
+			} else {
+				cells = append(cells, "-")
+			}
+		}
+		if len(windows) > 0 {
+			down := "-"
+			if len(row.Windows) == len(windows) {
+				down = (time.Duration(row.Windows[len(windows)-1].Down) * time.Second).String()
+			}
+			cells = append(cells, down)
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("report: write table: %w", err)
+	}
+	return nil
+}
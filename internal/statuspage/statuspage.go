@@ -0,0 +1,323 @@
+// Package statuspage renders a static status site from stored health
+// history: an overview of every product and a page per product, each a
+// single HTML file with no external assets.
+package statuspage
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/report"
+)
+
+//go:embed page.html.tmpl
+var files embed.FS
+
+var pageTemplate = template.Must(template.ParseFS(files, "page.html.tmpl"))
+
+// Site is everything a status site shows.
+type Site struct {
+	Title       string
+	GeneratedAt time.Time
+	Products    []Product
+	ShowReasons bool // list health reasons, which can name internal hosts, on incidents
+}
+
+// Product is one product's status history.
+type Product struct {
+	Name      string
+	Domain    string
+	Status    string            // latest health status; "" when never checked
+	Days      []domain.Uptime   // one per UTC day, oldest first, the last one ending at Site.GeneratedAt
+	Incidents []domain.Incident // newest first
+}
+
+// Write renders site into dir, creating it as needed: index.html for the
+// overview and <slug>/index.html for each product. Existing pages are
+// overwritten; other files in dir are left alone.
+func Write(dir string, site Site) error {
+	slugs := productSlugs(site.Products)
+
+	overview := site.page("", site.Products)
+	overview.Products = make([]productView, 0, len(site.Products))
+	for i, p := range site.Products {
+		overview.Products = append(overview.Products, site.productView(p, slugs[i]))
+		overview.Incidents = append(overview.Incidents, site.incidentViews(p, slugs[i])...)
+	}
+	slices.SortStableFunc(overview.Incidents, func(a, b incidentView) int {
+		return b.startedAt.Compare(a.startedAt)
+	})
+	if err := writePage(filepath.Join(dir, "index.html"), overview); err != nil {
+		return err
+	}
+
+	for i, p := range site.Products {
+		page := site.page("../", site.Products[i:i+1])
+		page.Heading = p.Name
+		page.Products = []productView{site.productView(p, slugs[i])}
+		page.Incidents = site.incidentViews(p, slugs[i])
+		if err := writePage(filepath.Join(dir, slugs[i], "index.html"), page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type pageView struct {
+	Title       string
+	Heading     string // product name on a product page; "" on the overview
+	Root        string // relative path to the overview
+	GeneratedAt string
+	Banner      string
+	BannerClass string
+	FirstDay    string // legend under the oldest bar, e.g. "89 days ago"
+	Products    []productView
+	Incidents   []incidentView
+}
+
+type productView struct {
+	Name        string
+	Domain      string
+	Slug        string
+	Status      string
+	StatusClass string
+	Uptime      string
+	Days        []dayView
+}
+
+type dayView struct {
+	Class string
+	Title string
+}
+
+type incidentView struct {
+	Product  string
+	Slug     string
+	Status   string
+	Started  string
+	Ended    string // "" while ongoing
+	Duration string
+	Reason   string
+
+	startedAt time.Time
+}
+
+// page starts a page for products, with the site's header and footer.
+func (s Site) page(root string, products []Product) pageView {
+	page := pageView{
+		Title:       s.Title,
+		Root:        root,
+		GeneratedAt: formatTime(s.GeneratedAt),
+	}
+	dayCount := 0
+	for _, p := range products {
+		dayCount = max(dayCount, len(p.Days))
+	}
+	page.FirstDay = daysAgo(dayCount - 1)
+
+	// The banner reflects the worst current status of products.
+	worst, checked := "", false
+	for _, p := range products {
+		switch p.Status {
+		case "down":
+			worst = "down"
+		case "degraded":
+			if worst != "down" {
+				worst = "degraded"
+			}
+		}
+		checked = checked || p.Status != ""
+	}
+	switch {
+	case worst == "down":
+		page.Banner, page.BannerClass = "Major outage", "down"
+	case worst == "degraded":
+		page.Banner, page.BannerClass = "Degraded performance", "degraded"
+	case checked:
+		page.Banner, page.BannerClass = "All systems operational", "healthy"
+	default:
+		page.Banner, page.BannerClass = "No checks yet", "none"
+	}
+	return page
+}
+
+func (s Site) productView(p Product, slug string) productView {
+	view := productView{
+		Name:        p.Name,
+		Domain:      p.Domain,
+		Slug:        slug,
+		Status:      statusText(p.Status),
+		StatusClass: statusClass(p.Status),
+		Uptime:      "-",
+	}
+
+	var total domain.Uptime
+	firstDay := domain.StartOfDayUTC(s.GeneratedAt).AddDate(0, 0, -(len(p.Days) - 1))
+	for i, day := range p.Days {
+		total.Up += day.Up
+		total.Degraded += day.Degraded
+		total.Down += day.Down
+		view.Days = append(view.Days, dayView{
+			Class: dayClass(day),
+			Title: dayTitle(firstDay.AddDate(0, 0, i), day),
+		})
+	}
+	if percent, ok := total.Percent(); ok {
+		view.Uptime = report.FormatUptime(&percent)
+	}
+	return view
+}
+
+func (s Site) incidentViews(p Product, slug string) []incidentView {
+	views := make([]incidentView, 0, len(p.Incidents))
+	for _, i := range p.Incidents {
+		view := incidentView{
+			Product:  p.Name,
+			Slug:     slug,
+			Status:   i.Status,
+			Started:  formatTime(i.StartedAt),
+			Duration: report.FormatDuration(i.Duration(s.GeneratedAt)),
+
+			startedAt: i.StartedAt,
+		}
+		if !i.Ongoing() {
+			view.Ended = formatTime(i.EndedAt)
+		}
+		if s.ShowReasons {
+			view.Reason = i.Reason
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func writePage(path string, page pageView) error {
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, page); err != nil {
+		return fmt.Errorf("statuspage: render %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("statuspage: create directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("statuspage: write %s: %w", path, err)
+	}
+	return nil
+}
+
+// dayClass colors a day's bar: any downtime over 1% of the monitored time is
+// a major outage, and less, or only degraded checks, a minor one.
+func dayClass(day domain.Uptime) string {
+	monitored := day.Monitored()
+	switch {
+	case monitored == 0:
+		return "none"
+	case day.Down*100 > monitored:
+		return "down"
+	case day.Down > 0 || day.Degraded > 0:
+		return "degraded"
+	default:
+		return "healthy"
+	}
+}
+
+func dayTitle(date time.Time, day domain.Uptime) string {
+	title := date.Format("Jan 2, 2006")
+	percent, ok := day.Percent()
+	if !ok {
+		return title + ": no data"
+	}
+	title += ": " + report.FormatUptime(&percent) + " uptime"
+	if day.Down > 0 {
+		title += ", " + report.FormatDuration(day.Down) + " down"
+	}
+	if day.Degraded > 0 {
+		title += ", " + report.FormatDuration(day.Degraded) + " degraded"
+	}
+	return title
+}
+
+func statusText(status string) string {
+	switch status {
+	case "healthy":
+		return "Operational"
+	case "degraded":
+		return "Degraded"
+	case "down":
+		return "Down"
+	default:
+		return "Unknown"
+	}
+}
+
+func statusClass(status string) string {
+	switch status {
+	case "healthy", "degraded", "down":
+		return status
+	default:
+		return "none"
+	}
+}
+
+// productSlugs returns a distinct directory name for each product, in
+// order: its name lowercased with runs of other characters turned into
+// hyphens, numbered when two names collide.
+func productSlugs(products []Product) []string {
+	slugs := make([]string, 0, len(products))
+	seen := make(map[string]bool, len(products))
+	for _, p := range products {
+		base := slugify(p.Name)
+		slug := base
+		for n := 2; seen[slug]; n++ {
+			slug = base + "-" + strconv.Itoa(n)
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+	return slugs
+}
+
+func slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	if b.Len() == 0 {
+		return "product"
+	}
+	return b.String()
+}
+
+// daysAgo describes the UTC day n days before today.
+func daysAgo(n int) string {
+	switch {
+	case n <= 0:
+		return "Today"
+	case n == 1:
+		return "Yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", n)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("Jan 2, 2006 15:04 UTC")
+}
@@ -0,0 +1,153 @@
+package statuspage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+func TestProductSlugs(t *testing.T) {
+	products := []Product{{Name: "App"}, {Name: "My App!"}, {Name: "my-app"}, {Name: "日本"}, {Name: "  Docs  v2 "}}
+	want := []string{"app", "my-app", "my-app-2", "product", "docs-v2"}
+	if got := productSlugs(products); !reflect.DeepEqual(got, want) {
+		t.Errorf("productSlugs() = %v, want %v", got, want)
+	}
+}
+
+func TestDayClass(t *testing.T) {
+	tests := []struct {
+		name string
+		day  domain.Uptime
+		want string
+	}{
+		{name: "no data", want: "none"},
+		{name: "all up", day: domain.Uptime{Up: 24 * time.Hour}, want: "healthy"},
+		{name: "only degraded", day: domain.Uptime{Up: 23 * time.Hour, Degraded: time.Hour}, want: "degraded"},
+		{name: "brief outage", day: domain.Uptime{Up: 24 * time.Hour, Down: 10 * time.Minute}, want: "degraded"},
+		{name: "long outage", day: domain.Uptime{Up: 22 * time.Hour, Down: 2 * time.Hour}, want: "down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dayClass(tt.day); got != tt.want {
+				t.Errorf("dayClass(%+v) = %q, want %q", tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	site := Site{
+		Title:       "Acme Status",
+		GeneratedAt: now,
+		ShowReasons: true,
+		Products: []Product{
+			{
+				Name:   "App",
+				Domain: "app.example.com",
+				Status: "healthy",
+				Days:   []domain.Uptime{{}, {Up: 23 * time.Hour, Down: time.Hour}, {Up: 12 * time.Hour}},
+				Incidents: []domain.Incident{
+					{ProductName: "App", StartedAt: now.Add(-30 * time.Hour), EndedAt: now.Add(-29 * time.Hour), Status: "down", Reason: "status 503"},
+				},
+			},
+			{
+				Name:   "<Api>",
+				Status: "degraded",
+				Incidents: []domain.Incident{
+					{ProductName: "<Api>", StartedAt: now.Add(-time.Hour), Status: "degraded", Reason: "slow: 2.1s"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "overview shows every product and incident, newest first",
+			fn: func(t *testing.T) {
+				dir := t.TempDir()
+				if err := Write(dir, site); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				page := readPage(t, dir, "index.html")
+				for _, want := range []string{
+					"<title>Acme Status</title>",
+					`<div class="banner degraded">Degraded performance</div>`,
+					`href="app/index.html">App</a>`,
+					"&lt;Api&gt;",
+					`title="Mar 9, 2026: 95.83% uptime, 1h down"`,
+					"97.22% uptime",
+					"<span>2 days ago</span>", // the oldest of three bars
+					"Mar 10, 2026 11:00 UTC – ongoing · 1h",
+				} {
+					if !strings.Contains(page, want) {
+						t.Errorf("index.html missing %q", want)
+					}
+				}
+				if strings.Index(page, "slow: 2.1s") > strings.Index(page, "status 503") {
+					t.Errorf("index.html lists the older incident first")
+				}
+			},
+		},
+		{
+			name: "product page shows only that product",
+			fn: func(t *testing.T) {
+				dir := t.TempDir()
+				if err := Write(dir, site); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				page := readPage(t, dir, "app", "index.html")
+				for _, want := range []string{
+					"<title>App · Acme Status</title>",
+					`<div class="banner healthy">All systems operational</div>`,
+					`href="../index.html"`,
+					"status 503",
+				} {
+					if !strings.Contains(page, want) {
+						t.Errorf("app/index.html missing %q", want)
+					}
+				}
+				if strings.Contains(page, "slow: 2.1s") {
+					t.Errorf("app/index.html shows another product's incident")
+				}
+				readPage(t, dir, "api", "index.html")
+			},
+		},
+		{
+			name: "hides reasons unless shown",
+			fn: func(t *testing.T) {
+				dir := t.TempDir()
+				hidden := site
+				hidden.ShowReasons = false
+				if err := Write(dir, hidden); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				if page := readPage(t, dir, "index.html"); strings.Contains(page, "status 503") {
+					t.Errorf("index.html shows a reason without ShowReasons set")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
+
+func readPage(t *testing.T, dir string, path ...string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(append([]string{dir}, path...)...))
+	if err != nil {
+		t.Fatalf("read page: %v", err)
+	}
+	return string(data)
+}
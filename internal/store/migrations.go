@@ -0,0 +1,340 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migration is one forward schema change. Migrations run in version order,
+// each in its own transaction together with its schema_version row, so a
+// failure leaves the database at the previous version.
+type migration struct {
+	version     int
+	description string
+	apply       func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations is append-only: never edit or reorder an entry that has shipped,
+// add a new version instead. Early versions use IF NOT EXISTS and column
+// checks because databases created before schema_version existed may already
+// contain part of their changes.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "metrics snapshots",
+		apply: execStatements(`
+			CREATE TABLE IF NOT EXISTS metrics_snapshots (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				product_name TEXT NOT NULL,
+				timestamp INTEGER NOT NULL,
+				visits INTEGER DEFAULT 0,
+				uniques INTEGER DEFAULT 0,
+				bounce_rate REAL DEFAULT 0,
+				mrr INTEGER DEFAULT 0,
+				subscribers INTEGER DEFAULT 0,
+				health_status TEXT DEFAULT '',
+				response_time INTEGER DEFAULT 0,
+				created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+			)`, `
+			CREATE INDEX IF NOT EXISTS idx_metrics_product_time
+			ON metrics_snapshots(product_name, timestamp DESC)`,
+		),
+	},
+	{
+		version:     2,
+		description: "sentry, vercel and github snapshot columns",
+		apply: addColumns("metrics_snapshots", []column{
+			{"unresolved_issues", "INTEGER DEFAULT 0"},
+			{"error_events", "INTEGER DEFAULT 0"},
+			{"new_issues", "INTEGER DEFAULT 0"},
+			{"deploy_state", "TEXT DEFAULT ''"},
+			{"deployed_at", "INTEGER DEFAULT 0"},
+			{"build_duration", "INTEGER DEFAULT 0"},
+			{"failed_deploys", "INTEGER DEFAULT 0"},
+			{"commits", "INTEGER DEFAULT 0"},
+			{"open_issues", "INTEGER DEFAULT 0"},
+			{"open_prs", "INTEGER DEFAULT 0"},
+			{"stars", "INTEGER DEFAULT 0"},
+			{"ci_status", "TEXT DEFAULT ''"},
+		}),
+	},
+	{
+		version:     3,
+		description: "daily metrics time series",
+		apply:       migrateDailyMetrics,
+	},
+	{
+		version:     4,
+		description: "generic snapshot values",
+		apply: execStatements(`
+			CREATE TABLE snapshot_values (
+				snapshot_id INTEGER NOT NULL REFERENCES metrics_snapshots(id) ON DELETE CASCADE,
+				key TEXT NOT NULL,
+				value REAL,
+				label TEXT,
+				PRIMARY KEY (snapshot_id, key)
+			)`,
+		),
+	},
+	{
+		version:     5,
+		description: "consecutive health check failures",
+		apply: execStatements(`
+			CREATE TABLE health_state (
+				product_name TEXT PRIMARY KEY,
+				consecutive_failures INTEGER NOT NULL DEFAULT 0,
+				updated_at INTEGER NOT NULL
+			)`,
+		),
+	},
+	{
+		version:     6,
+		description: "health reason and TLS certificate snapshot columns",
+		apply: addColumns("metrics_snapshots", []column{
+			{"health_reason", "TEXT DEFAULT ''"},
+			{"cert_expires_at", "INTEGER DEFAULT 0"},
+			{"cert_issuer", "TEXT DEFAULT ''"},
+			{"cert_error", "TEXT DEFAULT ''"},
+			{"cert_expiring", "INTEGER DEFAULT 0"},
+		}),
+	},
+	{
+		version:     7,
+		description: "domain registration cache and snapshot columns",
+		apply: func(ctx context.Context, tx *sql.Tx) error {
+			if err := execStatements(`
+				CREATE TABLE domain_registrations (
+					domain TEXT PRIMARY KEY,
+					registrar TEXT NOT NULL DEFAULT '',
+					expires_at INTEGER NOT NULL DEFAULT 0,
+					error TEXT NOT NULL DEFAULT '',
+					checked_at INTEGER NOT NULL
+				)`,
+			)(ctx, tx); err != nil {
+				return err
+			}
+			return addColumns("metrics_snapshots", []column{
+				{"domain_expires_at", "INTEGER DEFAULT 0"},
+				{"domain_registrar", "TEXT DEFAULT ''"},
+			})(ctx, tx)
+		},
+	},
+	{
+		version:     8,
+		description: "health check phase timing snapshot columns",
+		apply: addColumns("metrics_snapshots", []column{
+			{"dns_time", "INTEGER DEFAULT 0"},
+			{"connect_time", "INTEGER DEFAULT 0"},
+			{"tls_time", "INTEGER DEFAULT 0"},
+			{"ttfb", "INTEGER DEFAULT 0"},
+			{"download_time", "INTEGER DEFAULT 0"},
+		}),
+	},
+	{
+		version:     9,
+		description: "health incidents",
+		apply: execStatements(`
+			CREATE TABLE incidents (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				product_name TEXT NOT NULL,
+				started_at INTEGER NOT NULL,
+				ended_at INTEGER NOT NULL DEFAULT 0,
+				status TEXT NOT NULL,
+				reason TEXT NOT NULL DEFAULT ''
+			)`, `
+			CREATE INDEX idx_incidents_product_started
+			ON incidents(product_name, started_at)`,
+		),
+	},
+	{
+		version:     10,
+		description: "stripe subscription MRR tracking",
+		apply: execStatements(`
+			CREATE TABLE subscription_mrr (
+				subscription_id TEXT NOT NULL,
+				stripe_product TEXT NOT NULL,
+				day TEXT NOT NULL,
+				opening_mrr INTEGER,
+				mrr INTEGER NOT NULL,
+				PRIMARY KEY (subscription_id, stripe_product)
+			)`,
+		),
+	},
+	{
+		version:     11,
+		description: "move domain registration snapshot columns to snapshot_values",
+		apply: execStatements(`
+			INSERT OR IGNORE INTO snapshot_values (snapshot_id, key, value)
+			SELECT id, 'domain.expires_at', domain_expires_at
+			FROM metrics_snapshots WHERE domain_expires_at > 0`, `
+			INSERT OR IGNORE INTO snapshot_values (snapshot_id, key, label)
+			SELECT id, 'domain.registrar', domain_registrar
+			FROM metrics_snapshots WHERE domain_registrar != ''`, `
+			ALTER TABLE metrics_snapshots DROP COLUMN domain_expires_at`, `
+			ALTER TABLE metrics_snapshots DROP COLUMN domain_registrar`,
+		),
+	},
+}
+
+// migrate brings the database up to the latest schema version.
+func (s *Store) migrate() error {
+	ctx := context.Background()
+
+	if _, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL DEFAULT '',
+			applied_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+		)
+	`); err != nil {
+		return fmt.Errorf("store: create schema_version: %w", err)
+	}
+
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if latest := migrations[len(migrations)-1].version; current > latest {
+		return fmt.Errorf("store: database schema version %d is newer than this build supports (%d)", current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.applyMigration(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchemaVersion returns the highest applied migration version, or 0 for a
+// database that predates versioning.
+func (s *Store) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("store: read schema version: %w", err)
+	}
+	return version, nil
+}
+
+func (s *Store) applyMigration(ctx context.Context, m migration) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: begin migration %d: %w", m.version, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := m.apply(ctx, tx); err != nil {
+		return fmt.Errorf("store: migration %d (%s): %w", m.version, m.description, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO schema_version (version, description) VALUES (?, ?)
+	`, m.version, m.description); err != nil {
+		return fmt.Errorf("store: record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
+
+// execStatements returns a migration step that runs each statement in order.
+func execStatements(statements ...string) func(context.Context, *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type column struct{ name, def string }
+
+// addColumns returns a migration step that adds each column unless the table
+// already has it.
+func addColumns(table string, columns []column) func(context.Context, *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		existing, err := tableColumns(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		for _, col := range columns {
+			if existing[col.name] {
+				continue
+			}
+			// #nosec G202 -- table, column and def are compile-time constants from migrations.
+			if _, err := tx.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+col.name+` `+col.def); err != nil {
+				return fmt.Errorf("add column %s.%s: %w", table, col.name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("inspect %s: %w", table, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("inspect %s: %w", table, err)
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("inspect %s: %w", table, err)
+	}
+	return columns, nil
+}
+
+// migrateDailyMetrics creates daily_metrics and folds the earlier per-day
+// traffic table into it. Existing daily_metrics rows win.
+func migrateDailyMetrics(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS daily_metrics (
+			product_name TEXT NOT NULL,
+			day TEXT NOT NULL,
+			metric TEXT NOT NULL,
+			value INTEGER NOT NULL DEFAULT 0,
+			updated_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+			PRIMARY KEY (product_name, day, metric)
+		)
+	`); err != nil {
+		return err
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'daily_traffic'
+	`).Scan(&count); err != nil {
+		return fmt.Errorf("inspect daily_traffic: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	return execStatements(`
+		INSERT OR IGNORE INTO daily_metrics (product_name, day, metric, value, updated_at)
+		SELECT product_name, day, 'visits', visits, updated_at FROM daily_traffic`, `
+		INSERT OR IGNORE INTO daily_metrics (product_name, day, metric, value, updated_at)
+		SELECT product_name, day, 'uniques', uniques, updated_at FROM daily_traffic`, `
+		DROP TABLE daily_traffic`,
+	)(ctx, tx)
+}
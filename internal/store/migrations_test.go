@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createBaselineDB writes a database with the schema shipped before
+// versioned migrations, holding two snapshots.
+func createBaselineDB(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open baseline db: %v", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS metrics_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			product_name TEXT NOT NULL,
+			timestamp INTEGER NOT NULL,
+			visits INTEGER DEFAULT 0,
+			uniques INTEGER DEFAULT 0,
+			bounce_rate REAL DEFAULT 0,
+			mrr INTEGER DEFAULT 0,
+			subscribers INTEGER DEFAULT 0,
+			health_status TEXT DEFAULT '',
+			response_time INTEGER DEFAULT 0,
+			created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+		);
+		CREATE INDEX IF NOT EXISTS idx_metrics_product_time
+		ON metrics_snapshots(product_name, timestamp DESC);
+		INSERT INTO metrics_snapshots (product_name, timestamp, visits, mrr, health_status, response_time)
+		VALUES ('App', 100, 7, 1299, 'healthy', 120), ('App', 200, 9, 1299, 'down', 0);
+	`); err != nil {
+		t.Fatalf("create baseline schema: %v", err)
+	}
+	return path
+}
+
+func appliedVersions(t *testing.T, store *Store) []int {
+	t.Helper()
+
+	rows, err := store.db.Query(`SELECT version FROM schema_version ORDER BY version`)
+	if err != nil {
+		t.Fatalf("select schema_version: %v", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var versions []int
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("scan schema_version: %v", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func TestMigrate(t *testing.T) {
+	latest := migrations[len(migrations)-1].version
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "versions are contiguous from one",
+			fn: func(t *testing.T) {
+				for i, m := range migrations {
+					if m.version != i+1 {
+						t.Fatalf("migrations[%d].version = %d, want %d", i, m.version, i+1)
+					}
+				}
+			},
+		},
+		{
+			name: "new database reaches latest version",
+			fn: func(t *testing.T) {
+				store := openTestStore(t, ":memory:")
+				got, err := store.SchemaVersion(context.Background())
+				if err != nil {
+					t.Fatalf("SchemaVersion() error = %v", err)
+				}
+				if got != latest {
+					t.Fatalf("SchemaVersion() = %d, want %d", got, latest)
+				}
+			},
+		},
+		{
+			name: "upgrades baseline database without losing snapshots",
+			fn: func(t *testing.T) {
+				path := createBaselineDB(t)
+				store := openTestStore(t, path)
+				ctx := context.Background()
+
+				versions := appliedVersions(t, store)
+				if len(versions) != len(migrations) || versions[0] != 1 || versions[len(versions)-1] != latest {
+					t.Fatalf("applied versions = %v, want 1..%d", versions, latest)
+				}
+
+				history, err := store.GetMetricsRange(ctx, "App", time.Unix(0, 0), time.Unix(300, 0))
+				if err != nil {
+					t.Fatalf("GetMetricsRange() error = %v", err)
+				}
+				if len(history) != 2 || history[0].Visits != 7 || history[1].HealthStatus != "down" || history[0].MRR != 1299 {
+					t.Fatalf("GetMetricsRange() = %#v, want both baseline snapshots", history)
+				}
+			},
+		},
+		{
+			name: "reopening applies nothing",
+			fn: func(t *testing.T) {
+				path := createBaselineDB(t)
+				first, err := Open(path)
+				if err != nil {
+					t.Fatalf("Open() error = %v", err)
+				}
+				_ = first.Close()
+
+				store := openTestStore(t, path)
+				if versions := appliedVersions(t, store); len(versions) != len(migrations) {
+					t.Fatalf("applied versions = %v, want each once", versions)
+				}
+			},
+		},
+		{
+			name: "failed migration rolls back",
+			fn: func(t *testing.T) {
+				original := migrations
+				t.Cleanup(func() { migrations = original })
+				migrations = append(append([]migration(nil), original...), migration{
+					version:     latest + 1,
+					description: "broken",
+					apply: func(ctx context.Context, tx *sql.Tx) error {
+						if _, err := tx.ExecContext(ctx, `CREATE TABLE half_done (id INTEGER)`); err != nil {
+							return err
+						}
+						return errors.New("boom")
+					},
+				})
+
+				path := createBaselineDB(t)
+				if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "boom") {
+					t.Fatalf("Open() error = %v, want migration failure", err)
+				}
+
+				migrations = original
+				store := openTestStore(t, path)
+				if got, _ := store.SchemaVersion(context.Background()); got != latest {
+					t.Fatalf("SchemaVersion() = %d, want %d", got, latest)
+				}
+				var tables int
+				if err := store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'`).Scan(&tables); err != nil {
+					t.Fatalf("inspect tables: %v", err)
+				}
+				if tables != 0 {
+					t.Fatalf("half_done table survived a failed migration")
+				}
+			},
+		},
+		{
+			name: "refuses newer schema",
+			fn: func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "metrics.db")
+				store, err := Open(path)
+				if err != nil {
+					t.Fatalf("Open() error = %v", err)
+				}
+				if _, err := store.db.Exec(`INSERT INTO schema_version (version, description) VALUES (?, 'future')`, latest+1); err != nil {
+					t.Fatalf("insert future version: %v", err)
+				}
+				_ = store.Close()
+
+				if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "newer") {
+					t.Fatalf("Open() error = %v, want newer schema error", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
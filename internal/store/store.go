@@ -46,6 +46,10 @@ func Open(path string) (*Store, error) {
 		return nil, fmt.Errorf("store: open %s: %w", path, err)
 	}
 
+	// One connection serializes writes from concurrent product fetches (SQLite
+	// allows a single writer) and keeps ":memory:" databases to one instance.
+	db.SetMaxOpenConns(1)
+
 	store := &Store{db: db}
 	if err := store.migrate(); err != nil {
 		_ = db.Close()
@@ -59,13 +63,23 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-// SaveMetrics inserts a metrics snapshot
-func (s *Store) SaveMetrics(ctx context.Context, m *domain.Metrics) error {
+// SaveMetrics inserts a metrics snapshot together with its generic values
+func (s *Store) SaveMetrics(ctx context.Context, m *domain.Metrics) (err error) {
 	if m == nil {
 		return fmt.Errorf("store: metrics is nil")
 	}
 
-	_, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: begin metrics: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, `
 		INSERT INTO metrics_snapshots (
 			product_name,
 			timestamp,
@@ -75,80 +89,246 @@ func (s *Store) SaveMetrics(ctx context.Context, m *domain.Metrics) error {
 			mrr,
 			subscribers,
 			health_status,
-			response_time
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, m.ProductName, m.Timestamp.Unix(), m.Visits, m.Uniques, m.BounceRate, m.MRR, m.Subscribers, m.HealthStatus, m.ResponseTime)
+			response_time,
+			unresolved_issues,
+			error_events,
+			new_issues,
+			deploy_state,
+			deployed_at,
+			build_duration,
+			failed_deploys,
+			commits,
+			open_issues,
+			open_prs,
+			stars,
+			ci_status,
+			health_reason,
+			cert_expires_at,
+			cert_issuer,
+			cert_error,
+			cert_expiring,
+			dns_time,
+			connect_time,
+			tls_time,
+			ttfb,
+			download_time
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, m.ProductName, m.Timestamp.Unix(), m.Visits, m.Uniques, m.BounceRate, m.MRR, m.Subscribers, m.HealthStatus, m.ResponseTime,
+		m.UnresolvedIssues, m.ErrorEvents, m.NewIssues,
+		m.DeployState, unixOrZero(m.DeployedAt), m.BuildDuration, m.FailedDeploys,
+		m.Commits, m.OpenIssues, m.OpenPRs, m.Stars, m.CIStatus,
+		m.HealthReason, unixOrZero(m.CertExpiresAt), m.CertIssuer, m.CertError, m.CertExpiring,
+		m.DNSTime, m.ConnectTime, m.TLSTime, m.TTFB, m.DownloadTime)
 	if err != nil {
 		return fmt.Errorf("store: insert metrics: %w", err)
 	}
 
+	if len(m.Values)+len(m.Labels) > 0 {
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("store: snapshot id: %w", err)
+		}
+		for key, value := range m.Values {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO snapshot_values (snapshot_id, key, value) VALUES (?, ?, ?)
+			`, id, key, value); err != nil {
+				return fmt.Errorf("store: insert value %s: %w", key, err)
+			}
+		}
+		for key, label := range m.Labels {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO snapshot_values (snapshot_id, key, label) VALUES (?, ?, ?)
+				ON CONFLICT (snapshot_id, key) DO UPDATE SET label = excluded.label
+			`, id, key, label); err != nil {
+				return fmt.Errorf("store: insert label %s: %w", key, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: commit metrics: %w", err)
+	}
+	return nil
+}
+
+// snapshotColumns is the SELECT list shared by snapshot queries; keep in sync
+// with scanSnapshot.
+const snapshotColumns = `
+	id,
+	product_name,
+	timestamp,
+	COALESCE(visits, 0),
+	COALESCE(uniques, 0),
+	COALESCE(bounce_rate, 0),
+	COALESCE(mrr, 0),
+	COALESCE(subscribers, 0),
+	COALESCE(health_status, ''),
+	COALESCE(response_time, 0),
+	COALESCE(unresolved_issues, 0),
+	COALESCE(error_events, 0),
+	COALESCE(new_issues, 0),
+	COALESCE(deploy_state, ''),
+	COALESCE(deployed_at, 0),
+	COALESCE(build_duration, 0),
+	COALESCE(failed_deploys, 0),
+	COALESCE(commits, 0),
+	COALESCE(open_issues, 0),
+	COALESCE(open_prs, 0),
+	COALESCE(stars, 0),
+	COALESCE(ci_status, ''),
+	COALESCE(health_reason, ''),
+	COALESCE(cert_expires_at, 0),
+	COALESCE(cert_issuer, ''),
+	COALESCE(cert_error, ''),
+	COALESCE(cert_expiring, 0),
+	COALESCE(dns_time, 0),
+	COALESCE(connect_time, 0),
+	COALESCE(tls_time, 0),
+	COALESCE(ttfb, 0),
+	COALESCE(download_time, 0)
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSnapshot scans one snapshotColumns row, returning the snapshot id used
+// to attach its generic values.
+func scanSnapshot(row rowScanner) (int64, *domain.Metrics, error) {
+	var (
+		id            int64
+		m             domain.Metrics
+		ts            int64
+		deployedAt    int64
+		certExpiresAt int64
+	)
+	if err := row.Scan(
+		&id,
+		&m.ProductName,
+		&ts,
+		&m.Visits,
+		&m.Uniques,
+		&m.BounceRate,
+		&m.MRR,
+		&m.Subscribers,
+		&m.HealthStatus,
+		&m.ResponseTime,
+		&m.UnresolvedIssues,
+		&m.ErrorEvents,
+		&m.NewIssues,
+		&m.DeployState,
+		&deployedAt,
+		&m.BuildDuration,
+		&m.FailedDeploys,
+		&m.Commits,
+		&m.OpenIssues,
+		&m.OpenPRs,
+		&m.Stars,
+		&m.CIStatus,
+		&m.HealthReason,
+		&certExpiresAt,
+		&m.CertIssuer,
+		&m.CertError,
+		&m.CertExpiring,
+		&m.DNSTime,
+		&m.ConnectTime,
+		&m.TLSTime,
+		&m.TTFB,
+		&m.DownloadTime,
+	); err != nil {
+		return 0, nil, err
+	}
+	m.Timestamp = time.Unix(ts, 0)
+	if deployedAt > 0 {
+		m.DeployedAt = time.Unix(deployedAt, 0)
+	}
+	if certExpiresAt > 0 {
+		m.CertExpiresAt = time.Unix(certExpiresAt, 0)
+	}
+	return id, &m, nil
+}
+
+// attachValues loads generic values for the given snapshots, keyed by id.
+func (s *Store) attachValues(ctx context.Context, snapshots map[int64]*domain.Metrics, where string, args ...any) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	// #nosec G202 -- where is a constant fragment supplied by the callers below.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT v.snapshot_id, v.key, v.value, v.label
+		FROM snapshot_values v
+		JOIN metrics_snapshots m ON m.id = v.snapshot_id
+		WHERE `+where, args...)
+	if err != nil {
+		return fmt.Errorf("store: select snapshot values: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var (
+			id    int64
+			key   string
+			value sql.NullFloat64
+			label sql.NullString
+		)
+		if err := rows.Scan(&id, &key, &value, &label); err != nil {
+			return fmt.Errorf("store: scan snapshot values: %w", err)
+		}
+		m := snapshots[id]
+		if m == nil {
+			continue
+		}
+		if value.Valid {
+			m.SetValue(key, value.Float64)
+		}
+		if label.Valid {
+			m.SetLabel(key, label.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("store: iterate snapshot values: %w", err)
+	}
 	return nil
 }
 
+// unixOrZero stores unset times as 0 rather than year-1 seconds.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
 // GetLatestMetrics returns the most recent metrics for a product
 func (s *Store) GetLatestMetrics(ctx context.Context, productName string) (*domain.Metrics, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT
-			product_name,
-			timestamp,
-			COALESCE(visits, 0),
-			COALESCE(uniques, 0),
-			COALESCE(bounce_rate, 0),
-			COALESCE(mrr, 0),
-			COALESCE(subscribers, 0),
-			COALESCE(health_status, ''),
-			COALESCE(response_time, 0)
+		SELECT `+snapshotColumns+`
 		FROM metrics_snapshots
 		WHERE product_name = ?
 		ORDER BY timestamp DESC
 		LIMIT 1
 	`, productName)
 
-	var (
-		name         string
-		ts           int64
-		visits       int64
-		uniques      int64
-		bounceRate   float64
-		mrr          int64
-		subscribers  int64
-		healthStatus string
-		responseTime int64
-	)
-
-	if err := row.Scan(&name, &ts, &visits, &uniques, &bounceRate, &mrr, &subscribers, &healthStatus, &responseTime); err != nil {
+	id, m, err := scanSnapshot(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("store: select latest metrics: %w", err)
 	}
-
-	return &domain.Metrics{
-		ProductName:  name,
-		Timestamp:    time.Unix(ts, 0),
-		Visits:       visits,
-		Uniques:      uniques,
-		BounceRate:   bounceRate,
-		MRR:          mrr,
-		Subscribers:  subscribers,
-		HealthStatus: healthStatus,
-		ResponseTime: responseTime,
-	}, nil
+	if err := s.attachValues(ctx, map[int64]*domain.Metrics{id: m}, `m.id = ?`, id); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
 // GetMetricsRange returns metrics for a product within a time range (for charts)
 func (s *Store) GetMetricsRange(ctx context.Context, productName string, from, to time.Time) ([]*domain.Metrics, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT
-			product_name,
-			timestamp,
-			COALESCE(visits, 0),
-			COALESCE(uniques, 0),
-			COALESCE(bounce_rate, 0),
-			COALESCE(mrr, 0),
-			COALESCE(subscribers, 0),
-			COALESCE(health_status, ''),
-			COALESCE(response_time, 0)
+		SELECT `+snapshotColumns+`
 		FROM metrics_snapshots
 		WHERE product_name = ?
 			AND timestamp BETWEEN ? AND ?
@@ -162,69 +342,531 @@ func (s *Store) GetMetricsRange(ctx context.Context, productName string, from, t
 	}()
 
 	var metrics []*domain.Metrics
+	byID := make(map[int64]*domain.Metrics)
+	for rows.Next() {
+		id, m, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("store: scan metrics range: %w", err)
+		}
+		metrics = append(metrics, m)
+		byID[id] = m
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store: iterate metrics range: %w", err)
+	}
+	_ = rows.Close()
+
+	if err := s.attachValues(ctx, byID, `m.product_name = ? AND m.timestamp BETWEEN ? AND ?`, productName, from.Unix(), to.Unix()); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// SaveDailyMetrics upserts daily values for a product. Rows are keyed by
+// (product, UTC day, metric), so later writes for the same day overwrite
+// earlier ones rather than adding rows.
+func (s *Store) SaveDailyMetrics(ctx context.Context, productName string, values []domain.DailyValue) (err error) {
+	if len(values) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: begin daily metrics: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO daily_metrics (product_name, day, metric, value, updated_at)
+		VALUES (?, ?, ?, ?, strftime('%s', 'now'))
+		ON CONFLICT (product_name, day, metric) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return fmt.Errorf("store: prepare daily metrics: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, v := range values {
+		if v.Metric == "" {
+			return fmt.Errorf("store: daily metric name is empty")
+		}
+		if _, err := stmt.ExecContext(ctx, productName, v.Day.UTC().Format(dayLayout), v.Metric, v.Value); err != nil {
+			return fmt.Errorf("store: upsert daily metric %s: %w", v.Metric, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: commit daily metrics: %w", err)
+	}
+	return nil
+}
+
+// GetDailySeries returns one metric's stored values for a product for the
+// UTC days from..to inclusive, ordered by day. Missing days are omitted.
+func (s *Store) GetDailySeries(ctx context.Context, productName, metric string, from, to time.Time) ([]domain.DailyValue, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT day, metric, value
+		FROM daily_metrics
+		WHERE product_name = ?
+			AND metric = ?
+			AND day BETWEEN ? AND ?
+		ORDER BY day
+	`, productName, metric, from.UTC().Format(dayLayout), to.UTC().Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("store: select daily series: %w", err)
+	}
+	return scanDailyValues(rows)
+}
+
+// GetDailyMetrics returns every stored metric for a product for the UTC days
+// from..to inclusive, keyed by metric name and ordered by day.
+func (s *Store) GetDailyMetrics(ctx context.Context, productName string, from, to time.Time) (map[string][]domain.DailyValue, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT day, metric, value
+		FROM daily_metrics
+		WHERE product_name = ?
+			AND day BETWEEN ? AND ?
+		ORDER BY metric, day
+	`, productName, from.UTC().Format(dayLayout), to.UTC().Format(dayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("store: select daily metrics: %w", err)
+	}
+	values, err := scanDailyValues(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[string][]domain.DailyValue)
+	for _, v := range values {
+		series[v.Metric] = append(series[v.Metric], v)
+	}
+	return series, nil
+}
+
+func scanDailyValues(rows *sql.Rows) ([]domain.DailyValue, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var values []domain.DailyValue
 	for rows.Next() {
 		var (
-			name         string
-			ts           int64
-			visits       int64
-			uniques      int64
-			bounceRate   float64
-			mrr          int64
-			subscribers  int64
-			healthStatus string
-			responseTime int64
+			day string
+			v   domain.DailyValue
 		)
+		if err := rows.Scan(&day, &v.Metric, &v.Value); err != nil {
+			return nil, fmt.Errorf("store: scan daily metrics: %w", err)
+		}
+		parsed, err := time.Parse(dayLayout, day)
+		if err != nil {
+			return nil, fmt.Errorf("store: parse daily metric day %q: %w", day, err)
+		}
+		v.Day = parsed
+		values = append(values, v)
+	}
 
-		if err := rows.Scan(&name, &ts, &visits, &uniques, &bounceRate, &mrr, &subscribers, &healthStatus, &responseTime); err != nil {
-			return nil, fmt.Errorf("store: scan metrics range: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store: iterate daily metrics: %w", err)
+	}
+	return values, nil
+}
+
+// dayLayout is the storage format for UTC calendar days.
+const dayLayout = "2006-01-02"
+
+// RecordHealthCheck extends productName's run of consecutive failed health
+// checks when failed is set, or ends it, and returns the run's length.
+func (s *Store) RecordHealthCheck(ctx context.Context, productName string, failed bool, at time.Time) (int, error) {
+	var failures int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO health_state (product_name, consecutive_failures, updated_at)
+		VALUES (?, CASE WHEN ? THEN 1 ELSE 0 END, ?)
+		ON CONFLICT (product_name) DO UPDATE SET
+			consecutive_failures = CASE WHEN ? THEN health_state.consecutive_failures + 1 ELSE 0 END,
+			updated_at = excluded.updated_at
+		RETURNING consecutive_failures
+	`, productName, failed, at.Unix(), failed).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("store: record health check: %w", err)
+	}
+	return failures, nil
+}
+
+// GetDomainRegistration returns the cached registration of a registrable
+// domain, or nil when it has never been looked up.
+func (s *Store) GetDomainRegistration(ctx context.Context, name string) (*domain.DomainRegistration, error) {
+	var (
+		reg       = domain.DomainRegistration{Domain: name}
+		expiresAt int64
+		checkedAt int64
+	)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT registrar, expires_at, error, checked_at
+		FROM domain_registrations
+		WHERE domain = ?
+	`, name).Scan(&reg.Registrar, &expiresAt, &reg.Error, &checkedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("store: get domain registration: %w", err)
+	}
+	if expiresAt > 0 {
+		reg.ExpiresAt = time.Unix(expiresAt, 0)
+	}
+	reg.CheckedAt = time.Unix(checkedAt, 0)
+	return &reg, nil
+}
+
+// SaveDomainRegistration replaces the cached registration of reg.Domain.
+func (s *Store) SaveDomainRegistration(ctx context.Context, reg domain.DomainRegistration) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO domain_registrations (domain, registrar, expires_at, error, checked_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (domain) DO UPDATE SET
+			registrar = excluded.registrar,
+			expires_at = excluded.expires_at,
+			error = excluded.error,
+			checked_at = excluded.checked_at
+	`, reg.Domain, reg.Registrar, unixOrZero(reg.ExpiresAt), reg.Error, reg.CheckedAt.Unix()); err != nil {
+		return fmt.Errorf("store: save domain registration: %w", err)
+	}
+	return nil
+}
+
+// GetUptime weighs productName's health snapshots by how long each status
+// held over [from, to]. A snapshot's status holds until the next snapshot,
+// for at most maxGap, so a stretch with the daemon stopped counts as
+// unmonitored rather than as whatever the last check said. The snapshot
+// before from, if any, covers the start of the window.
+func (s *Store) GetUptime(ctx context.Context, productName string, from, to time.Time, maxGap time.Duration) (domain.Uptime, error) {
+	uptime := domain.Uptime{Window: to.Sub(from)}
+	samples, err := s.healthSamples(ctx, productName, from, to)
+	if err != nil {
+		return uptime, err
+	}
+	weighUptime(&uptime, samples, from, to, maxGap)
+	return uptime, nil
+}
+
+// GetDailyUptime is GetUptime for each 24 hours from from, oldest first. The
+// last day ends at to, so it may be shorter.
+func (s *Store) GetDailyUptime(ctx context.Context, productName string, from, to time.Time, maxGap time.Duration) ([]domain.Uptime, error) {
+	samples, err := s.healthSamples(ctx, productName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var days []domain.Uptime
+	for start := from; start.Before(to); start = start.Add(24 * time.Hour) {
+		end := start.Add(24 * time.Hour)
+		if end.After(to) {
+			end = to
 		}
+		uptime := domain.Uptime{Window: end.Sub(start)}
+		weighUptime(&uptime, samples, start, end, maxGap)
+		days = append(days, uptime)
+	}
+	return days, nil
+}
 
-		metrics = append(metrics, &domain.Metrics{
-			ProductName:  name,
-			Timestamp:    time.Unix(ts, 0),
-			Visits:       visits,
-			Uniques:      uniques,
-			BounceRate:   bounceRate,
-			MRR:          mrr,
-			Subscribers:  subscribers,
-			HealthStatus: healthStatus,
-			ResponseTime: responseTime,
-		})
+// healthSamples returns productName's health statuses over [from, to],
+// starting with the last snapshot at or before from, in time order.
+func (s *Store) healthSamples(ctx context.Context, productName string, from, to time.Time) ([]healthSample, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT timestamp, COALESCE(health_status, '')
+		FROM metrics_snapshots
+		WHERE product_name = ?
+			AND timestamp <= ?
+			AND timestamp >= (
+				SELECT COALESCE(MAX(timestamp), 0)
+				FROM metrics_snapshots
+				WHERE product_name = ? AND timestamp <= ?
+			)
+		ORDER BY timestamp
+	`, productName, to.Unix(), productName, from.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("store: select uptime: %w", err)
 	}
+	defer func() {
+		_ = rows.Close()
+	}()
 
+	var samples []healthSample
+	for rows.Next() {
+		var (
+			ts     int64
+			sample healthSample
+		)
+		if err := rows.Scan(&ts, &sample.status); err != nil {
+			return nil, fmt.Errorf("store: scan uptime: %w", err)
+		}
+		sample.at = time.Unix(ts, 0)
+		samples = append(samples, sample)
+	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("store: iterate metrics range: %w", err)
+		return nil, fmt.Errorf("store: iterate uptime: %w", err)
 	}
+	return samples, nil
+}
 
-	return metrics, nil
+type healthSample struct {
+	at     time.Time
+	status string
 }
 
-// migrate creates the schema if it doesn't exist
-func (s *Store) migrate() error {
-	if _, err := s.db.Exec(`
-		CREATE TABLE IF NOT EXISTS metrics_snapshots (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			product_name TEXT NOT NULL,
-			timestamp INTEGER NOT NULL,
-			visits INTEGER DEFAULT 0,
-			uniques INTEGER DEFAULT 0,
-			bounce_rate REAL DEFAULT 0,
-			mrr INTEGER DEFAULT 0,
-			subscribers INTEGER DEFAULT 0,
-			health_status TEXT DEFAULT '',
-			response_time INTEGER DEFAULT 0,
-			created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
-		);
-	`); err != nil {
-		return fmt.Errorf("store: migrate schema: %w", err)
-	}
-
-	if _, err := s.db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_metrics_product_time
-		ON metrics_snapshots(product_name, timestamp DESC);
-	`); err != nil {
-		return fmt.Errorf("store: migrate index: %w", err)
+// weighUptime adds each sample's share of [from, to] to uptime. samples are
+// in time order.
+func weighUptime(uptime *domain.Uptime, samples []healthSample, from, to time.Time, maxGap time.Duration) {
+	for i, sample := range samples {
+		end := sample.at.Add(maxGap)
+		if i+1 < len(samples) && samples[i+1].at.Before(end) {
+			end = samples[i+1].at
+		}
+		if end.After(to) {
+			end = to
+		}
+		start := sample.at
+		if start.Before(from) {
+			start = from
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		switch held := end.Sub(start); sample.status {
+		case "healthy":
+			uptime.Up += held
+		case "degraded":
+			uptime.Degraded += held
+		case "down":
+			uptime.Down += held
+		}
+	}
+}
+
+// TrackIncident folds a health check into productName's incidents. A
+// degraded or down status opens an incident, or escalates the open one from
+// degraded to down; a healthy status closes it. Any other status, such as the
+// empty one of a product without a health check, changes nothing.
+func (s *Store) TrackIncident(ctx context.Context, productName, status, reason string, at time.Time) (err error) {
+	if status != "healthy" && status != "degraded" && status != "down" {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: begin incident: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		id    int64
+		worst string
+	)
+	open := true
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, status
+		FROM incidents
+		WHERE product_name = ? AND ended_at = 0
+		ORDER BY started_at DESC
+		LIMIT 1
+	`, productName).Scan(&id, &worst)
+	if err == sql.ErrNoRows {
+		open, err = false, nil
+	}
+	if err != nil {
+		return fmt.Errorf("store: get open incident: %w", err)
+	}
+
+	switch {
+	case status == "healthy" && open:
+		_, err = tx.ExecContext(ctx, `UPDATE incidents SET ended_at = ? WHERE id = ?`, at.Unix(), id)
+	case status == "healthy":
+		// Nothing to close.
+	case !open:
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO incidents (product_name, started_at, status, reason)
+			VALUES (?, ?, ?, ?)
+		`, productName, at.Unix(), status, reason)
+	case status == "down" && worst != "down":
+		_, err = tx.ExecContext(ctx, `UPDATE incidents SET status = ?, reason = ? WHERE id = ?`, status, reason, id)
+	}
+	if err != nil {
+		return fmt.Errorf("store: save incident: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: commit incident: %w", err)
+	}
 	return nil
 }
+
+// GetIncidents returns every product's incidents that were ongoing at some
+// point since from, newest first.
+func (s *Store) GetIncidents(ctx context.Context, from time.Time) ([]domain.Incident, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, product_name, started_at, ended_at, status, reason
+		FROM incidents
+		WHERE ended_at = 0 OR ended_at >= ?
+		ORDER BY started_at DESC, id DESC
+	`, from.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("store: select incidents: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var incidents []domain.Incident
+	for rows.Next() {
+		var (
+			incident  domain.Incident
+			startedAt int64
+			endedAt   int64
+		)
+		if err := rows.Scan(&incident.ID, &incident.ProductName, &startedAt, &endedAt, &incident.Status, &incident.Reason); err != nil {
+			return nil, fmt.Errorf("store: scan incident: %w", err)
+		}
+		incident.StartedAt = time.Unix(startedAt, 0)
+		if endedAt > 0 {
+			incident.EndedAt = time.Unix(endedAt, 0)
+		}
+		incidents = append(incidents, incident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store: iterate incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// TrackSubscriptionMRR records each subscription's current MRR as of day, a
+// UTC calendar day, and returns the MRR each one opened the day with: the
+// last MRR recorded on an earlier day. Subscriptions first recorded on day
+// are left out of the result. A product added to a subscription recorded
+// before day opens at 0, and a product recorded earlier but missing from
+// subs is recorded at 0, so both show up as a change in MRR.
+func (s *Store) TrackSubscriptionMRR(ctx context.Context, day time.Time, subs []domain.SubscriptionMRR) (opening []domain.SubscriptionMRR, err error) {
+	if len(subs) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("store: begin subscription mrr: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// A new row opens at 0 when its subscription was already recorded
+	// before day, and with no opening when the subscription is new.
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO subscription_mrr (subscription_id, stripe_product, day, opening_mrr, mrr)
+		VALUES (?1, ?2, ?3, (
+			SELECT 0 FROM subscription_mrr
+			WHERE subscription_id = ?1 AND (day < ?3 OR opening_mrr IS NOT NULL)
+			LIMIT 1
+		), ?4)
+		ON CONFLICT (subscription_id, stripe_product) DO UPDATE SET
+			opening_mrr = CASE
+				WHEN subscription_mrr.day < excluded.day THEN subscription_mrr.mrr
+				ELSE subscription_mrr.opening_mrr
+			END,
+			day = excluded.day,
+			mrr = excluded.mrr
+		RETURNING opening_mrr
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("store: prepare subscription mrr: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	track := func(sub domain.SubscriptionMRR) error {
+		var mrr sql.NullInt64
+		if err := stmt.QueryRowContext(ctx, sub.Subscription, sub.Product, day.UTC().Format(dayLayout), sub.MRR).Scan(&mrr); err != nil {
+			return fmt.Errorf("store: upsert subscription mrr: %w", err)
+		}
+		if mrr.Valid {
+			opening = append(opening, domain.SubscriptionMRR{Subscription: sub.Subscription, Product: sub.Product, MRR: mrr.Int64})
+		}
+		return nil
+	}
+
+	var (
+		ids      []string
+		products = make(map[string]map[string]bool)
+	)
+	for _, sub := range subs {
+		if err := track(sub); err != nil {
+			return nil, err
+		}
+		if products[sub.Subscription] == nil {
+			ids = append(ids, sub.Subscription)
+			products[sub.Subscription] = make(map[string]bool)
+		}
+		products[sub.Subscription][sub.Product] = true
+	}
+
+	// Products dropped from a subscription are now worth nothing to it.
+	for _, id := range ids {
+		recorded, err := subscriptionProducts(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range recorded {
+			if products[id][product] {
+				continue
+			}
+			if err := track(domain.SubscriptionMRR{Subscription: id, Product: product}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("store: commit subscription mrr: %w", err)
+	}
+	return opening, nil
+}
+
+// subscriptionProducts returns every Stripe product recorded for the
+// subscription with id.
+func subscriptionProducts(ctx context.Context, tx *sql.Tx, id string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT stripe_product FROM subscription_mrr WHERE subscription_id = ? ORDER BY stripe_product`, id)
+	if err != nil {
+		return nil, fmt.Errorf("store: query subscription products: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var products []string
+	for rows.Next() {
+		var product string
+		if err := rows.Scan(&product); err != nil {
+			return nil, fmt.Errorf("store: scan subscription product: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store: iterate subscription products: %w", err)
+	}
+	return products, nil
+}
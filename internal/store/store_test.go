@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -59,6 +60,71 @@ func TestOpen(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "adds columns to legacy schema",
+			fn: func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "metrics.db")
+				legacy, err := sql.Open("sqlite", path)
+				if err != nil {
+					t.Fatalf("open legacy db: %v", err)
+				}
+				if _, err := legacy.Exec(`
+					CREATE TABLE metrics_snapshots (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						product_name TEXT NOT NULL,
+						timestamp INTEGER NOT NULL,
+						visits INTEGER DEFAULT 0,
+						uniques INTEGER DEFAULT 0,
+						bounce_rate REAL DEFAULT 0,
+						mrr INTEGER DEFAULT 0,
+						subscribers INTEGER DEFAULT 0,
+						health_status TEXT DEFAULT '',
+						response_time INTEGER DEFAULT 0,
+						created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+					);
+					INSERT INTO metrics_snapshots (product_name, timestamp, visits) VALUES ('App', 100, 7);
+				`); err != nil {
+					t.Fatalf("create legacy schema: %v", err)
+				}
+				_ = legacy.Close()
+
+				store := openTestStore(t, path)
+				got, err := store.GetLatestMetrics(context.Background(), "App")
+				if err != nil {
+					t.Fatalf("GetLatestMetrics() error = %v", err)
+				}
+				if got == nil || got.Visits != 7 || got.UnresolvedIssues != 0 {
+					t.Fatalf("GetLatestMetrics() = %#v, want legacy row with zero new columns", got)
+				}
+			},
+		},
+		{
+			name: "moves domain registration columns into snapshot values",
+			fn: func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "metrics.db")
+				store := openTestStore(t, path)
+				// Roll the schema back to before migration 11.
+				if _, err := store.db.Exec(`
+					ALTER TABLE metrics_snapshots ADD COLUMN domain_expires_at INTEGER DEFAULT 0;
+					ALTER TABLE metrics_snapshots ADD COLUMN domain_registrar TEXT DEFAULT '';
+					INSERT INTO metrics_snapshots (product_name, timestamp, domain_expires_at, domain_registrar)
+					VALUES ('App', 100, 900000, 'Namecheap');
+					DELETE FROM schema_version WHERE version = 11;
+				`); err != nil {
+					t.Fatalf("restore version 10 schema: %v", err)
+				}
+				_ = store.Close()
+
+				store = openTestStore(t, path)
+				got, err := store.GetLatestMetrics(context.Background(), "App")
+				if err != nil {
+					t.Fatalf("GetLatestMetrics() error = %v", err)
+				}
+				if got == nil || !got.DomainExpiresAt().Equal(time.Unix(900000, 0)) || got.DomainRegistrar() != "Namecheap" {
+					t.Fatalf("GetLatestMetrics() = %#v, want registration moved to values and labels", got)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -123,7 +189,9 @@ func TestGetLatestMetrics(t *testing.T) {
 
 				metrics := []domain.Metrics{
 					{ProductName: "App", Timestamp: time.Unix(100, 0), Visits: 5},
-					{ProductName: "App", Timestamp: time.Unix(200, 0), Visits: 10},
+					{ProductName: "App", Timestamp: time.Unix(200, 0), Visits: 10, UnresolvedIssues: 4, ErrorEvents: 30, NewIssues: 1, DeployState: "READY", DeployedAt: time.Unix(150, 0), BuildDuration: 42, FailedDeploys: 2, Commits: 9, OpenIssues: 3, OpenPRs: 1, Stars: 50, CIStatus: "success",
+						HealthStatus: "degraded", HealthReason: "certificate expires 1970-01-05", CertExpiresAt: time.Unix(400000, 0), CertIssuer: "R11", CertExpiring: true,
+						ResponseTime: 180, DNSTime: 12, ConnectTime: 20, TLSTime: 40, TTFB: 100, DownloadTime: 8},
 				}
 				for i := range metrics {
 					if err := store.SaveMetrics(ctx, &metrics[i]); err != nil {
@@ -139,15 +207,74 @@ func TestGetLatestMetrics(t *testing.T) {
 					t.Fatalf("GetLatestMetrics() = nil, want metrics")
 				}
 				want := &domain.Metrics{
-					ProductName: "App",
-					Timestamp:   time.Unix(200, 0),
-					Visits:      10,
+					ProductName:      "App",
+					Timestamp:        time.Unix(200, 0),
+					Visits:           10,
+					UnresolvedIssues: 4,
+					ErrorEvents:      30,
+					NewIssues:        1,
+					DeployState:      "READY",
+					DeployedAt:       time.Unix(150, 0),
+					BuildDuration:    42,
+					FailedDeploys:    2,
+					Commits:          9,
+					OpenIssues:       3,
+					OpenPRs:          1,
+					Stars:            50,
+					CIStatus:         "success",
+					HealthStatus:     "degraded",
+					HealthReason:     "certificate expires 1970-01-05",
+					CertExpiresAt:    time.Unix(400000, 0),
+					CertIssuer:       "R11",
+					ResponseTime:     180,
+					DNSTime:          12,
+					ConnectTime:      20,
+					TLSTime:          40,
+					TTFB:             100,
+					DownloadTime:     8,
+					CertExpiring:     true,
 				}
 				if !reflect.DeepEqual(got, want) {
 					t.Fatalf("GetLatestMetrics() = %#v, want %#v", got, want)
 				}
 			},
 		},
+		{
+			name: "round-trips generic values",
+			fn: func(t *testing.T) {
+				store := openTestStore(t, ":memory:")
+				ctx := context.Background()
+
+				saved := &domain.Metrics{ProductName: "App", Timestamp: time.Unix(100, 0)}
+				saved.SetValue("plausible.visitors", 42.5)
+				saved.SetLabel("plausible.plan", "growth")
+				if err := store.SaveMetrics(ctx, saved); err != nil {
+					t.Fatalf("SaveMetrics() error = %v", err)
+				}
+				if err := store.SaveMetrics(ctx, &domain.Metrics{ProductName: "Other", Timestamp: time.Unix(100, 0)}); err != nil {
+					t.Fatalf("SaveMetrics() error = %v", err)
+				}
+
+				got, err := store.GetLatestMetrics(ctx, "App")
+				if err != nil {
+					t.Fatalf("GetLatestMetrics() error = %v", err)
+				}
+				if !reflect.DeepEqual(got.Values, saved.Values) || !reflect.DeepEqual(got.Labels, saved.Labels) {
+					t.Fatalf("GetLatestMetrics() values = %v labels = %v, want %v %v", got.Values, got.Labels, saved.Values, saved.Labels)
+				}
+
+				history, err := store.GetMetricsRange(ctx, "App", time.Unix(0, 0), time.Unix(200, 0))
+				if err != nil {
+					t.Fatalf("GetMetricsRange() error = %v", err)
+				}
+				if len(history) != 1 || history[0].Values["plausible.visitors"] != 42.5 {
+					t.Fatalf("GetMetricsRange() = %#v, want values attached", history)
+				}
+				if other, _ := store.GetLatestMetrics(ctx, "Other"); other.Values != nil {
+					t.Fatalf("Other values = %v, want nil", other.Values)
+				}
+			},
+		},
 		{
 			name: "returns nil for missing product",
 			fn: func(t *testing.T) {
@@ -238,3 +365,404 @@ func TestGetMetricsRange(t *testing.T) {
 		t.Run(tt.name, tt.fn)
 	}
 }
+
+func TestDailyMetrics(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T)
+	}{
+		{
+			name: "upserts by product, day and metric",
+			fn: func(t *testing.T) {
+				store := openTestStore(t, ":memory:")
+				ctx := context.Background()
+
+				if err := store.SaveDailyMetrics(ctx, "App", []domain.DailyValue{
+					{Day: day(1), Metric: domain.DailyVisits, Value: 10},
+					{Day: day(2), Metric: domain.DailyVisits, Value: 20},
+					{Day: day(2), Metric: domain.DailyMRR, Value: 500},
+				}); err != nil {
+					t.Fatalf("SaveDailyMetrics() error = %v", err)
+				}
+				if err := store.SaveDailyMetrics(ctx, "App", []domain.DailyValue{
+					{Day: day(2).Add(15 * time.Hour), Metric: domain.DailyVisits, Value: 25},
+					{Day: day(3), Metric: domain.DailyVisits, Value: 30},
+				}); err != nil {
+					t.Fatalf("SaveDailyMetrics() error = %v", err)
+				}
+				if err := store.SaveDailyMetrics(ctx, "Other", []domain.DailyValue{{Day: day(2), Metric: domain.DailyVisits, Value: 99}}); err != nil {
+					t.Fatalf("SaveDailyMetrics() error = %v", err)
+				}
+
+				got, err := store.GetDailySeries(ctx, "App", domain.DailyVisits, day(2), day(3).Add(12*time.Hour))
+				if err != nil {
+					t.Fatalf("GetDailySeries() error = %v", err)
+				}
+				want := []domain.DailyValue{
+					{Day: day(2), Metric: domain.DailyVisits, Value: 25},
+					{Day: day(3), Metric: domain.DailyVisits, Value: 30},
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("GetDailySeries() = %#v, want %#v", got, want)
+				}
+
+				all, err := store.GetDailyMetrics(ctx, "App", day(1), day(3))
+				if err != nil {
+					t.Fatalf("GetDailyMetrics() error = %v", err)
+				}
+				if len(all) != 2 || len(all[domain.DailyVisits]) != 3 || all[domain.DailyMRR][0].Value != 500 {
+					t.Fatalf("GetDailyMetrics() = %#v, want visits and mrr series", all)
+				}
+			},
+		},
+		{
+			name: "rejects empty metric name",
+			fn: func(t *testing.T) {
+				store := openTestStore(t, ":memory:")
+				err := store.SaveDailyMetrics(context.Background(), "App", []domain.DailyValue{{Day: day(1), Value: 1}})
+				if err == nil {
+					t.Fatalf("SaveDailyMetrics() error = nil, want error")
+				}
+			},
+		},
+		{
+			name: "empty input is a no-op",
+			fn: func(t *testing.T) {
+				store := openTestStore(t, ":memory:")
+				if err := store.SaveDailyMetrics(context.Background(), "App", nil); err != nil {
+					t.Fatalf("SaveDailyMetrics() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "folds legacy daily_traffic table",
+			fn: func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "metrics.db")
+				legacy, err := sql.Open("sqlite", path)
+				if err != nil {
+					t.Fatalf("open legacy db: %v", err)
+				}
+				if _, err := legacy.Exec(`
+					CREATE TABLE daily_traffic (
+						product_name TEXT NOT NULL,
+						day TEXT NOT NULL,
+						visits INTEGER NOT NULL DEFAULT 0,
+						uniques INTEGER NOT NULL DEFAULT 0,
+						updated_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now')),
+						PRIMARY KEY (product_name, day)
+					);
+					INSERT INTO daily_traffic (product_name, day, visits, uniques) VALUES ('App', '2026-01-01', 12, 7);
+				`); err != nil {
+					t.Fatalf("create legacy schema: %v", err)
+				}
+				_ = legacy.Close()
+
+				store := openTestStore(t, path)
+				got, err := store.GetDailyMetrics(context.Background(), "App", day(1), day(1))
+				if err != nil {
+					t.Fatalf("GetDailyMetrics() error = %v", err)
+				}
+				if got[domain.DailyVisits][0].Value != 12 || got[domain.DailyUniques][0].Value != 7 {
+					t.Fatalf("GetDailyMetrics() = %#v, want migrated visits and uniques", got)
+				}
+
+				var tables int
+				if err := store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'daily_traffic'`).Scan(&tables); err != nil {
+					t.Fatalf("inspect tables: %v", err)
+				}
+				if tables != 0 {
+					t.Fatalf("daily_traffic still exists after migration")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.fn)
+	}
+}
+
+func TestRecordHealthCheck(t *testing.T) {
+	store := openTestStore(t, ":memory:")
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		product string
+		failed  bool
+		want    int
+	}{
+		{"App", true, 1},
+		{"App", true, 2},
+		{"Other", true, 1},
+		{"App", false, 0},
+		{"App", true, 1},
+		{"Other", false, 0},
+	}
+	for i, step := range steps {
+		got, err := store.RecordHealthCheck(ctx, step.product, step.failed, now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("step %d: RecordHealthCheck() error = %v", i, err)
+		}
+		if got != step.want {
+			t.Fatalf("step %d: RecordHealthCheck(%s, failed=%v) = %d, want %d", i, step.product, step.failed, got, step.want)
+		}
+	}
+}
+
+func TestDomainRegistration(t *testing.T) {
+	store := openTestStore(t, ":memory:")
+	ctx := context.Background()
+
+	got, err := store.GetDomainRegistration(ctx, "example.com")
+	if err != nil || got != nil {
+		t.Fatalf("GetDomainRegistration() = %v, %v, want nil, nil", got, err)
+	}
+
+	regs := []domain.DomainRegistration{
+		{Domain: "example.com", Registrar: "Namecheap", ExpiresAt: time.Unix(900000, 0), CheckedAt: time.Unix(100, 0)},
+		{Domain: "example.com", Error: "no RDAP service", CheckedAt: time.Unix(200, 0)},
+	}
+	for _, reg := range regs {
+		if err := store.SaveDomainRegistration(ctx, reg); err != nil {
+			t.Fatalf("SaveDomainRegistration() error = %v", err)
+		}
+		got, err := store.GetDomainRegistration(ctx, reg.Domain)
+		if err != nil {
+			t.Fatalf("GetDomainRegistration() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, &reg) {
+			t.Fatalf("GetDomainRegistration() = %+v, want %+v", got, reg)
+		}
+	}
+}
+
+func TestGetUptime(t *testing.T) {
+	store := openTestStore(t, ":memory:")
+	ctx := context.Background()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(4 * time.Hour)
+
+	snapshots := []struct {
+		product string
+		at      time.Duration // from the window start
+		status  string
+	}{
+		{"App", -2 * time.Hour, "down"}, // superseded before the window starts
+		{"App", -30 * time.Minute, "healthy"},
+		{"App", 30 * time.Minute, "down"},
+		{"App", time.Hour, "degraded"}, // holds for maxGap, then the daemon was off
+		{"App", 3 * time.Hour, "healthy"},
+		{"App", 3*time.Hour + 30*time.Minute, ""}, // no health check ran
+		{"App", 5 * time.Hour, "down"},            // after the window
+		{"Other", 2 * time.Hour, "down"},
+	}
+	for _, snap := range snapshots {
+		m := &domain.Metrics{ProductName: snap.product, Timestamp: from.Add(snap.at), HealthStatus: snap.status}
+		if err := store.SaveMetrics(ctx, m); err != nil {
+			t.Fatalf("SaveMetrics() error = %v", err)
+		}
+	}
+
+	got, err := store.GetUptime(ctx, "App", from, to, time.Hour)
+	if err != nil {
+		t.Fatalf("GetUptime() error = %v", err)
+	}
+	want := domain.Uptime{Window: 4 * time.Hour, Up: time.Hour, Degraded: time.Hour, Down: 30 * time.Minute}
+	if got != want {
+		t.Errorf("GetUptime() = %+v, want %+v", got, want)
+	}
+	if percent, ok := got.Percent(); !ok || percent < 79.9 || percent > 80.1 {
+		t.Errorf("Percent() = %v, %v, want 80, true", percent, ok)
+	}
+
+	got, err = store.GetUptime(ctx, "Missing", from, to, time.Hour)
+	if err != nil {
+		t.Fatalf("GetUptime() error = %v", err)
+	}
+	if _, ok := got.Percent(); ok || got.Monitored() != 0 {
+		t.Errorf("GetUptime(Missing) = %+v, want nothing monitored", got)
+	}
+}
+
+func TestTrackIncident(t *testing.T) {
+	store := openTestStore(t, ":memory:")
+	ctx := context.Background()
+	start := time.Unix(1767268800, 0) // the store reads times back in the local zone
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	checks := []struct {
+		product string
+		minutes int
+		status  string
+		reason  string
+	}{
+		{"App", 0, "healthy", ""}, // nothing to close
+		{"App", 10, "degraded", "slow: 2.1s"},
+		{"App", 20, "down", "status 503"},
+		{"App", 30, "degraded", "slow: 3s"}, // doesn't lower the worst status
+		{"App", 40, "", ""},                 // no health check ran
+		{"App", 50, "healthy", ""},
+		{"Other", 15, "down", "connection refused"},
+		{"App", 70, "degraded", "body: missing \"ok\""},
+	}
+	for _, c := range checks {
+		if err := store.TrackIncident(ctx, c.product, c.status, c.reason, at(c.minutes)); err != nil {
+			t.Fatalf("TrackIncident(%s, %q) error = %v", c.product, c.status, err)
+		}
+	}
+
+	got, err := store.GetIncidents(ctx, start)
+	if err != nil {
+		t.Fatalf("GetIncidents() error = %v", err)
+	}
+	want := []domain.Incident{
+		{ID: 3, ProductName: "App", StartedAt: at(70), Status: "degraded", Reason: "body: missing \"ok\""},
+		{ID: 2, ProductName: "Other", StartedAt: at(15), Status: "down", Reason: "connection refused"},
+		{ID: 1, ProductName: "App", StartedAt: at(10), EndedAt: at(50), Status: "down", Reason: "status 503"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetIncidents() = %+v, want %+v", got, want)
+	}
+	if d := got[2].Duration(at(100)); d != 40*time.Minute {
+		t.Errorf("Duration() = %v, want 40m", d)
+	}
+
+	got, err = store.GetIncidents(ctx, at(60))
+	if err != nil {
+		t.Fatalf("GetIncidents() error = %v", err)
+	}
+	if len(got) != 2 || !got[0].Ongoing() || !got[1].Ongoing() {
+		t.Errorf("GetIncidents(after the first ended) = %+v, want the 2 ongoing", got)
+	}
+}
+
+func TestGetDailyUptime(t *testing.T) {
+	store := openTestStore(t, ":memory:")
+	ctx := context.Background()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, snap := range []struct {
+		at     time.Duration
+		status string
+	}{
+		{-30 * time.Minute, "healthy"},          // carries into the first day
+		{23*time.Hour + 30*time.Minute, "down"}, // spans midnight
+		{24*time.Hour + 30*time.Minute, "healthy"},
+	} {
+		m := &domain.Metrics{ProductName: "App", Timestamp: from.Add(snap.at), HealthStatus: snap.status}
+		if err := store.SaveMetrics(ctx, m); err != nil {
+			t.Fatalf("SaveMetrics() error = %v", err)
+		}
+	}
+
+	got, err := store.GetDailyUptime(ctx, "App", from, from.Add(60*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatalf("GetDailyUptime() error = %v", err)
+	}
+	want := []domain.Uptime{
+		{Window: 24 * time.Hour, Up: 30 * time.Minute, Down: 30 * time.Minute},
+		{Window: 24 * time.Hour, Up: time.Hour, Down: 30 * time.Minute},
+		{Window: 12 * time.Hour},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDailyUptime() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrackSubscriptionMRR(t *testing.T) {
+	store := openTestStore(t, ":memory:")
+	ctx := context.Background()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name string
+		day  time.Time
+		subs []domain.SubscriptionMRR
+		want []domain.SubscriptionMRR
+	}{
+		{
+			name: "first seen",
+			day:  day,
+			subs: []domain.SubscriptionMRR{{Subscription: "sub_1", Product: "prod_a", MRR: 1000}},
+		},
+		{
+			name: "same day keeps no opening",
+			day:  day,
+			subs: []domain.SubscriptionMRR{{Subscription: "sub_1", Product: "prod_a", MRR: 1500}},
+		},
+		{
+			name: "next day opens with the last value",
+			day:  day.AddDate(0, 0, 1),
+			subs: []domain.SubscriptionMRR{
+				{Subscription: "sub_1", Product: "prod_a", MRR: 2000},
+				{Subscription: "sub_2", Product: "prod_a", MRR: 500},
+			},
+			want: []domain.SubscriptionMRR{{Subscription: "sub_1", Product: "prod_a", MRR: 1500}},
+		},
+		{
+			name: "later refresh the same day keeps the opening",
+			day:  day.AddDate(0, 0, 1),
+			subs: []domain.SubscriptionMRR{
+				{Subscription: "sub_1", Product: "prod_a", MRR: 1800},
+				{Subscription: "sub_2", Product: "prod_a", MRR: 500},
+			},
+			want: []domain.SubscriptionMRR{{Subscription: "sub_1", Product: "prod_a", MRR: 1500}},
+		},
+		{
+			name: "a gap of days opens with the last value recorded",
+			day:  day.AddDate(0, 0, 5),
+			subs: []domain.SubscriptionMRR{{Subscription: "sub_2", Product: "prod_a", MRR: 700}},
+			want: []domain.SubscriptionMRR{{Subscription: "sub_2", Product: "prod_a", MRR: 500}},
+		},
+		{
+			name: "a product added to a known subscription opens at zero",
+			day:  day.AddDate(0, 0, 6),
+			subs: []domain.SubscriptionMRR{
+				{Subscription: "sub_2", Product: "prod_a", MRR: 700},
+				{Subscription: "sub_2", Product: "prod_b", MRR: 300},
+			},
+			want: []domain.SubscriptionMRR{
+				{Subscription: "sub_2", Product: "prod_a", MRR: 700},
+				{Subscription: "sub_2", Product: "prod_b", MRR: 0},
+			},
+		},
+		{
+			name: "a product added to a new subscription has no opening",
+			day:  day.AddDate(0, 0, 6),
+			subs: []domain.SubscriptionMRR{
+				{Subscription: "sub_3", Product: "prod_a", MRR: 100},
+				{Subscription: "sub_3", Product: "prod_b", MRR: 200},
+			},
+		},
+		{
+			name: "a product dropped from a subscription is recorded at zero",
+			day:  day.AddDate(0, 0, 7),
+			subs: []domain.SubscriptionMRR{{Subscription: "sub_2", Product: "prod_b", MRR: 300}},
+			want: []domain.SubscriptionMRR{
+				{Subscription: "sub_2", Product: "prod_b", MRR: 300},
+				{Subscription: "sub_2", Product: "prod_a", MRR: 700},
+			},
+		},
+		{
+			name: "a dropped product stays at zero",
+			day:  day.AddDate(0, 0, 8),
+			subs: []domain.SubscriptionMRR{{Subscription: "sub_2", Product: "prod_b", MRR: 300}},
+			want: []domain.SubscriptionMRR{
+				{Subscription: "sub_2", Product: "prod_b", MRR: 300},
+				{Subscription: "sub_2", Product: "prod_a", MRR: 0},
+			},
+		},
+	}
+	for _, step := range steps {
+		got, err := store.TrackSubscriptionMRR(ctx, step.day, step.subs)
+		if err != nil {
+			t.Fatalf("%s: TrackSubscriptionMRR() error = %v", step.name, err)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("%s: TrackSubscriptionMRR() = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package tui
 import (
 	"context"
 	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,20 +18,34 @@ import (
 
 	"github.com/phaedrus/overmind/internal/domain"
 	"github.com/phaedrus/overmind/internal/providers"
+	"github.com/phaedrus/overmind/internal/report"
 )
 
 type Model struct {
 	products []domain.Product
 	metrics  map[string]*domain.Metrics // keyed by product name
 	fetcher  *providers.MetricsFetcher
+	offline  bool               // render cached metrics only; never fetch
+	columns  []providers.Column // registered-provider columns after the built-in ones
 
-	loading  bool
+	loading  bool                       // a fetch is in flight
+	inFlight map[string]map[string]bool // product being fetched -> providers reported so far
 	spinner  spinner.Model
 	err      error
 	width    int
 	height   int
 	selected int // currently selected product index
 
+	uptime  map[string][]domain.Uptime // per product, one per providers.UptimeWindows entry
+	detail  string                     // product shown in the detail view; "" shows the table
+	history []*domain.Metrics          // the detail product's recent snapshots, oldest first
+
+	showIncidents bool
+	incidents     []domain.Incident // newest first
+
+	showRevenue bool
+	revenue     map[string]domain.MRRMovement
+
 	viewport     viewport.Model
 	columnWidths columnWidths
 	tableWidth   int
@@ -45,9 +61,13 @@ const (
 	sortByVisits
 	sortByName
 	sortByHealth
+	sortByCommits
 )
 
-const columnGap = 2
+const (
+	columnGap   = 2
+	columnCount = 12
+)
 
 type columnWidths struct {
 	name    int
@@ -56,44 +76,88 @@ type columnWidths struct {
 	trend   int
 	mrr     int
 	subs    int
+	issues  int
+	deploy  int
+	commits int
 	health  int
 	latency int
+	uptime  int
+	extra   []int // one per Model.columns entry
+}
+
+// extraAt returns the width of the i-th registered-provider column, or 0
+// before the layout is known.
+func (c columnWidths) extraAt(i int) int {
+	if i < 0 || i >= len(c.extra) {
+		return 0
+	}
+	return c.extra[i]
 }
 
 func (c columnWidths) totalWidth() int {
-	sum := c.name + c.domain + c.visits + c.trend + c.mrr + c.subs + c.health + c.latency
+	sum := c.name + c.domain + c.visits + c.trend + c.mrr + c.subs + c.issues + c.deploy + c.commits + c.health + c.latency + c.uptime
+	for _, w := range c.extra {
+		sum += w
+	}
 	if sum == 0 {
 		return 0
 	}
-	return sum + columnGap*7
+	return sum + columnGap*(columnCount+len(c.extra)-1)
+}
+
+// Options configures the dashboard.
+type Options struct {
+	// Offline shows the last cached metrics and never touches the network.
+	Offline bool
 }
 
 // Messages
-type metricsLoadedMsg struct {
+type productUpdateMsg struct {
+	update  providers.Update
+	updates <-chan providers.Update
+}
+type fetchDoneMsg struct{}
+type cachedMetricsMsg struct {
 	metrics map[string]*domain.Metrics
+	err     error
+}
+type uptimeMsg struct {
+	uptime map[string][]domain.Uptime
+	err    error
 }
-type metricsErrorMsg struct{ err error }
 
-func New(products []domain.Product, f *providers.MetricsFetcher) *Model {
+func New(products []domain.Product, f *providers.MetricsFetcher, opts Options) *Model {
 	sp := spinner.New()
-	sp.Spinner = spinner.Dot
+	// Dot frames carry a trailing space; trim it so the glyph fits table cells.
+	sp.Spinner = spinner.Spinner{FPS: spinner.Dot.FPS}
+	for _, frame := range spinner.Dot.Frames {
+		sp.Spinner.Frames = append(sp.Spinner.Frames, strings.TrimSpace(frame))
+	}
 	sp.Style = lipgloss.NewStyle().Foreground(ColorTraction)
 
 	return &Model{
 		products: products,
 		metrics:  make(map[string]*domain.Metrics),
 		fetcher:  f,
+		offline:  opts.Offline,
+		columns:  f.Columns(products),
 		spinner:  sp,
-		loading:  true,
 		sortKey:  sortByMRR,
 		sortDesc: true,
 	}
 }
 
+// Init renders cached metrics straight away and, unless offline, revalidates
+// them with a live fetch.
 func (m *Model) Init() tea.Cmd {
+	if m.offline {
+		return tea.Batch(m.loadCached(), m.loadUptime())
+	}
 	return tea.Batch(
 		m.spinner.Tick,
-		m.fetchMetrics(),
+		m.loadCached(),
+		m.loadUptime(),
+		m.startFetch(),
 	)
 }
 
@@ -104,15 +168,46 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "r":
-			m.loading = true
 			m.err = nil
-			return m, m.fetchMetrics()
+			if m.offline {
+				return m, tea.Batch(m.loadCached(), m.loadUptime(), m.reloadIncidents(), m.reloadRevenue())
+			}
+			if m.loading {
+				return m, nil
+			}
+			return m, tea.Batch(m.spinner.Tick, m.startFetch())
 		case "s":
 			m.cycleSort()
 			m.sortProducts()
 			m.updateViewportContent()
 			m.syncViewport()
 			return m, nil
+		case "enter":
+			if m.showIncidents || m.showRevenue {
+				return m, nil
+			}
+			if m.detail == "" {
+				return m, m.openDetail()
+			}
+			m.detail = ""
+			return m, nil
+		case "i":
+			if !m.showIncidents {
+				return m, m.openIncidents()
+			}
+			m.showIncidents = false
+			return m, nil
+		case "v":
+			if !m.showRevenue {
+				return m, m.openRevenue()
+			}
+			m.showRevenue = false
+			return m, nil
+		case "esc", "backspace":
+			m.detail = ""
+			m.showIncidents = false
+			m.showRevenue = false
+			return m, nil
 		case "up", "k":
 			m.moveSelection(-1)
 			return m, nil
@@ -132,22 +227,87 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.syncViewport()
 		return m, nil
 	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
+		if len(m.inFlight) > 0 {
+			// Row spinners live in the viewport content.
+			m.updateViewportContent()
+		}
 		return m, cmd
-	case metricsLoadedMsg:
-		m.loading = false
-		m.metrics = msg.metrics
+	case cachedMetricsMsg:
+		if msg.err != nil {
+			// The cache is a head start; a live fetch may still be on its way.
+			m.err = msg.err
+		}
+		// A live result that beat the cache read wins.
+		for name, metric := range msg.metrics {
+			if current := m.metrics[name]; current == nil || current.Stale {
+				m.metrics[name] = metric
+			}
+		}
 		m.sortProducts()
+		m.updateLayout()
 		m.updateViewportContent()
 		m.syncViewport()
 		return m, nil
-	case metricsErrorMsg:
+	case productUpdateMsg:
+		u := msg.update
+		m.metrics[u.ProductName] = u.Metrics
+		var reload tea.Cmd
+		if u.Done {
+			delete(m.inFlight, u.ProductName)
+			m.sortProducts()
+			if u.ProductName == m.detail {
+				// The new snapshot is stored now; show it among the recent checks.
+				reload = m.loadHistory()
+			}
+		} else if done := m.inFlight[u.ProductName]; done != nil {
+			done[u.Provider] = true
+		}
+		m.updateViewportContent()
+		m.syncViewport()
+		return m, tea.Batch(waitForUpdate(msg.updates), reload)
+	case historyMsg:
+		if msg.product != m.detail {
+			return m, nil
+		}
+		m.history = msg.metrics
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		return m, nil
+	case fetchDoneMsg:
 		m.loading = false
-		m.err = msg.err
-		m.updateLayout()
+		m.inFlight = nil
+		m.sortProducts()
 		m.updateViewportContent()
 		m.syncViewport()
+		// Every product's new snapshot is stored now.
+		return m, tea.Batch(m.loadUptime(), m.reloadIncidents(), m.reloadRevenue())
+	case incidentsMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.incidents = msg.incidents
+		return m, nil
+	case revenueMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.revenue = msg.movements
+		return m, nil
+	case uptimeMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.uptime = msg.uptime
+		m.updateViewportContent()
 		return m, nil
 	}
 	var cmd tea.Cmd
@@ -156,10 +316,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) View() string {
-	if m.loading {
+	if m.loading && len(m.metrics) == 0 {
 		return m.loadingView()
 	}
 
+	if m.showIncidents {
+		return m.incidentsView()
+	}
+	if m.showRevenue {
+		return m.revenueView()
+	}
+	if m.detail != "" {
+		return m.detailView()
+	}
+
 	var b strings.Builder
 	b.WriteString(TitleStyle.Render("PRODUCTS"))
 
@@ -173,7 +343,7 @@ func (m *Model) View() string {
 	b.WriteString("\n")
 	b.WriteString(m.statusView())
 	b.WriteString("\n")
-	b.WriteString(HelpStyle.Render("r refresh • s sort • q quit • j/k navigate"))
+	b.WriteString(HelpStyle.Render("r refresh • s sort • enter details • i incidents • v revenue • q quit • j/k navigate"))
 
 	return b.String()
 }
@@ -215,8 +385,12 @@ func (m *Model) headerView() string {
 	trend := "TREND"
 	mrr := "MRR"
 	subs := "SUBS"
+	issues := "ISSUES"
+	deploy := "DEPLOY"
+	commits := "COMMITS"
 	health := "HEALTH"
 	latency := "LATENCY"
+	uptime := "UP 30D"
 
 	switch m.sortKey {
 	case sortByMRR:
@@ -227,23 +401,35 @@ func (m *Model) headerView() string {
 		name = fmt.Sprintf("NAME %s", sortIndicator(m.sortDesc))
 	case sortByHealth:
 		health = fmt.Sprintf("HEALTH %s", sortIndicator(m.sortDesc))
+	case sortByCommits:
+		commits = fmt.Sprintf("COMMITS %s", sortIndicator(m.sortDesc))
 	}
 
-	return joinColumns(
+	cols := []string{
 		header.name.Render(truncate(name, widths.name)),
 		header.domain.Render(truncate(domain, widths.domain)),
 		header.visits.Render(truncate(visits, widths.visits)),
 		header.trend.Render(truncate(trend, widths.trend)),
 		header.mrr.Render(truncate(mrr, widths.mrr)),
 		header.subs.Render(truncate(subs, widths.subs)),
+		header.issues.Render(truncate(issues, widths.issues)),
+		header.deploy.Render(truncate(deploy, widths.deploy)),
+		header.commits.Render(truncate(commits, widths.commits)),
 		header.health.Render(truncate(health, widths.health)),
 		header.latency.Render(truncate(latency, widths.latency)),
-	)
+		header.uptime.Render(truncate(uptime, widths.uptime)),
+	}
+	for i, c := range m.columns {
+		cols = append(cols, header.extra(widths, i).Render(truncate(c.Title, widths.extraAt(i))))
+	}
+	return joinColumns(cols...)
 }
 
 func (m *Model) statusView() string {
 	totalMRR := int64(0)
 	totalVisits := int64(0)
+	failedDeploys := 0
+	renewalsDue := 0
 	for _, p := range m.products {
 		metrics := m.metrics[p.Name]
 		if metrics == nil {
@@ -251,6 +437,12 @@ func (m *Model) statusView() string {
 		}
 		totalMRR += metrics.MRR
 		totalVisits += metrics.Visits
+		if metrics.DeployState == "ERROR" {
+			failedDeploys++
+		}
+		if slices.Contains(metrics.Warnings(), domain.SignalDomainExpiring) {
+			renewalsDue++
+		}
 	}
 
 	status := fmt.Sprintf("Total: %s MRR • %s visits • %d products",
@@ -258,6 +450,18 @@ func (m *Model) statusView() string {
 		formatNumber(totalVisits),
 		len(m.products),
 	)
+	if failedDeploys > 0 {
+		status = fmt.Sprintf("%s • %s", status, ErrorStyle.Render(fmt.Sprintf("%d failed deploys", failedDeploys)))
+	}
+	if renewalsDue > 0 {
+		status = fmt.Sprintf("%s • %s", status, WarningStyle.Render(fmt.Sprintf("%d domain renewals due", renewalsDue)))
+	}
+	switch {
+	case m.offline:
+		status = fmt.Sprintf("%s • offline", status)
+	case m.loading:
+		status = fmt.Sprintf("%s • %s refreshing %d/%d", status, m.spinner.View(), len(m.products)-len(m.inFlight), len(m.products))
+	}
 
 	if m.rowCount > m.viewport.Height && m.viewport.Height > 0 {
 		start := m.viewport.YOffset + 1
@@ -275,8 +479,18 @@ type columnStyleSet struct {
 	trend   lipgloss.Style
 	mrr     lipgloss.Style
 	subs    lipgloss.Style
+	issues  lipgloss.Style
+	deploy  lipgloss.Style
+	commits lipgloss.Style
 	health  lipgloss.Style
 	latency lipgloss.Style
+	uptime  lipgloss.Style
+	base    lipgloss.Style
+}
+
+// extra returns the style for the i-th registered-provider column.
+func (c columnStyleSet) extra(widths columnWidths, i int) lipgloss.Style {
+	return c.base.Width(max(0, widths.extraAt(i))).Align(lipgloss.Right)
 }
 
 func columnStyles(widths columnWidths, base lipgloss.Style) columnStyleSet {
@@ -287,8 +501,13 @@ func columnStyles(widths columnWidths, base lipgloss.Style) columnStyleSet {
 		trend:   base.Width(max(0, widths.trend)).Align(lipgloss.Center),
 		mrr:     base.Width(max(0, widths.mrr)).Align(lipgloss.Right),
 		subs:    base.Width(max(0, widths.subs)).Align(lipgloss.Right),
+		issues:  base.Width(max(0, widths.issues)).Align(lipgloss.Right),
+		deploy:  base.Width(max(0, widths.deploy)).Align(lipgloss.Center),
+		commits: base.Width(max(0, widths.commits)).Align(lipgloss.Right),
 		health:  base.Width(max(0, widths.health)).Align(lipgloss.Center),
 		latency: base.Width(max(0, widths.latency)).Align(lipgloss.Right),
+		uptime:  base.Width(max(0, widths.uptime)).Align(lipgloss.Right),
+		base:    base,
 	}
 }
 
@@ -322,14 +541,29 @@ func (m *Model) renderRow(product domain.Product, metrics *domain.Metrics, selec
 		}
 	}
 
+	name := product.Name
+	if metrics != nil && metrics.Stale {
+		// Cached values: mute the row and show how old they are.
+		rowStyle = TableRowMutedStyle
+		nameStyle = TableRowMutedStyle
+		name = fmt.Sprintf("%s (%s)", product.Name, formatAge(time.Since(metrics.Timestamp)))
+	}
+
 	styles := columnStyles(widths, rowStyle)
-	nameCell := nameStyle.Width(max(0, widths.name)).Render(truncate(product.Name, widths.name))
+	nameCell := nameStyle.Width(max(0, widths.name)).Render(truncate(name, widths.name))
+	_, fetching := m.inFlight[product.Name]
+	if fetching && widths.name > 2 {
+		nameCell = m.spinner.View() + " " + nameStyle.Width(widths.name-2).Render(truncate(name, widths.name-2))
+	}
 	domainCell := styles.domain.Render(truncate(product.Domain, widths.domain))
 
 	visits := "0"
 	trend := ""
 	mrr := "$0.00"
 	subs := "0"
+	issues := "-"
+	deploy := "-"
+	commits := "-"
 	health := SubtitleStyle.Render("●")
 	latency := "n/a"
 
@@ -338,22 +572,67 @@ func (m *Model) renderRow(product domain.Product, metrics *domain.Metrics, selec
 		trend = renderSparkline(metrics.VisitsHistory, widths.trend, rowStyle)
 		mrr = formatCurrency(metrics.MRR)
 		subs = formatNumber(metrics.Subscribers)
-		health = healthDot(metrics.HealthStatus)
+		if product.SentryProject != "" {
+			issues = formatIssues(metrics.UnresolvedIssues, metrics.NewIssues)
+		}
+		if product.VercelProjectID != "" {
+			deploy = deployCell(metrics.DeployState, metrics.DeployedAt, time.Now())
+		}
+		if product.GitHubRepo != "" {
+			commits = ciDot(metrics.CIStatus) + " " + formatNumber(metrics.Commits)
+		}
+		health = healthDot(metrics.HealthStatus) + certBadge(metrics)
 		if metrics.ResponseTime > 0 {
 			latency = fmt.Sprintf("%dms", metrics.ResponseTime)
 		}
 	}
 
-	row := joinColumns(
+	if fetching && metrics != nil && !metrics.Stale {
+		// Live partial result: cells whose provider hasn't reported yet spin.
+		done := m.inFlight[product.Name]
+		spin := m.spinner.View()
+		if product.PostHogHost != "" && !done[providers.ProviderPostHog] {
+			visits, trend = spin, spin
+		}
+		if product.StripeID != "" && !done[providers.ProviderStripe] {
+			mrr, subs = spin, spin
+		}
+		if product.SentryProject != "" && !done[providers.ProviderSentry] {
+			issues = spin
+		}
+		if product.VercelProjectID != "" && !done[providers.ProviderVercel] {
+			deploy = spin
+		}
+		if product.GitHubRepo != "" && !done[providers.ProviderGitHub] {
+			commits = spin
+		}
+		if product.Domain != "" && !done[providers.ProviderHealth] {
+			health, latency = spin, spin
+		}
+	}
+
+	cols := []string{
 		nameCell,
 		domainCell,
 		styles.visits.Render(visits),
 		styles.trend.Render(trend),
 		styles.mrr.Render(mrr),
 		styles.subs.Render(subs),
+		styles.issues.Render(issues),
+		styles.deploy.Render(deploy),
+		styles.commits.Render(commits),
 		styles.health.Render(health),
 		styles.latency.Render(latency),
-	)
+		styles.uptime.Render(uptimeCell(m.uptime[product.Name])),
+	}
+	for i, c := range m.columns {
+		cell := formatColumn(c, metrics)
+		if fetching && metrics != nil && !metrics.Stale && !m.inFlight[product.Name][c.Provider] {
+			cell = m.spinner.View()
+		}
+		cols = append(cols, styles.extra(widths, i).Render(truncate(cell, widths.extraAt(i))))
+	}
+	row := joinColumns(cols...)
 
 	if selected {
 		return TableRowSelectedStyle.Render(row)
@@ -456,6 +735,9 @@ func (m *Model) cycleSort() {
 		m.sortKey = sortByVisits
 		m.sortDesc = true
 	case sortByVisits:
+		m.sortKey = sortByCommits
+		m.sortDesc = true
+	case sortByCommits:
 		m.sortKey = sortByName
 		m.sortDesc = false
 	case sortByName:
@@ -494,6 +776,16 @@ func (m *Model) sortProducts() {
 				return av > bv
 			}
 			return av < bv
+		case sortByCommits:
+			ac := metricCommits(ma)
+			bc := metricCommits(mb)
+			if ac == bc {
+				return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+			}
+			if m.sortDesc {
+				return ac > bc
+			}
+			return ac < bc
 		case sortByName:
 			an := strings.ToLower(a.Name)
 			bn := strings.ToLower(b.Name)
@@ -553,8 +845,12 @@ func (m *Model) calcColumnWidths() columnWidths {
 		trend:   7,
 		mrr:     8,
 		subs:    5,
+		issues:  8,
+		deploy:  8,
+		commits: 8,
 		health:  6,
 		latency: 7,
+		uptime:  7,
 	}
 
 	minName := 12
@@ -562,7 +858,14 @@ func (m *Model) calcColumnWidths() columnWidths {
 	minNameFloor := 6
 	minDomainFloor := 8
 
-	available := width - fixed.visits - fixed.trend - fixed.mrr - fixed.subs - fixed.health - fixed.latency - columnGap*7
+	for _, c := range m.columns {
+		fixed.extra = append(fixed.extra, max(c.Width, lipgloss.Width(c.Title)))
+	}
+
+	available := width - fixed.visits - fixed.trend - fixed.mrr - fixed.subs - fixed.issues - fixed.deploy - fixed.commits - fixed.health - fixed.latency - fixed.uptime - columnGap*(columnCount+len(fixed.extra)-1)
+	for _, w := range fixed.extra {
+		available -= w
+	}
 	if available <= 0 {
 		return fixed
 	}
@@ -591,8 +894,13 @@ func (m *Model) calcColumnWidths() columnWidths {
 		trend:   fixed.trend,
 		mrr:     fixed.mrr,
 		subs:    fixed.subs,
+		issues:  fixed.issues,
+		deploy:  fixed.deploy,
+		commits: fixed.commits,
 		health:  fixed.health,
 		latency: fixed.latency,
+		uptime:  fixed.uptime,
+		extra:   fixed.extra,
 	}
 }
 
@@ -610,6 +918,13 @@ func metricVisits(m *domain.Metrics) int64 {
 	return m.Visits
 }
 
+func metricCommits(m *domain.Metrics) int64 {
+	if m == nil {
+		return 0
+	}
+	return m.Commits
+}
+
 func metricHealth(m *domain.Metrics) string {
 	if m == nil {
 		return ""
@@ -650,6 +965,84 @@ func healthDot(status string) string {
 	}
 }
 
+// uptimeCell renders the longest window's uptime, flagging anything under
+// 99.9% and, worse, under 99%.
+func uptimeCell(windows []domain.Uptime) string {
+	if len(windows) == 0 {
+		return "-"
+	}
+	percent, ok := windows[len(windows)-1].Percent()
+	switch {
+	case !ok:
+		return "-"
+	case percent < 99:
+		return ErrorStyle.Render(report.FormatUptime(&percent))
+	case percent < 99.9:
+		return WarningStyle.Render(report.FormatUptime(&percent))
+	default:
+		return report.FormatUptime(&percent)
+	}
+}
+
+// certBadge flags a TLS certificate that failed verification or is close to
+// expiring, next to the health dot.
+func certBadge(metrics *domain.Metrics) string {
+	switch {
+	case metrics.CertError != "":
+		return " " + ErrorStyle.Render("tls")
+	case metrics.CertExpiring:
+		return " " + WarningStyle.Render(fmt.Sprintf("%dd", metrics.CertDaysLeft()))
+	default:
+		return ""
+	}
+}
+
+// ciDot colors the latest default-branch CI conclusion.
+func ciDot(conclusion string) string {
+	switch conclusion {
+	case "success":
+		return HealthyStyle.Render("●")
+	case "failure", "timed_out", "startup_failure":
+		return ErrorStyle.Render("●")
+	case "cancelled", "action_required":
+		return WarningStyle.Render("●")
+	default:
+		return SubtitleStyle.Render("●")
+	}
+}
+
+// deployCell renders the latest production deploy state with its age.
+func deployCell(state string, deployedAt, now time.Time) string {
+	switch state {
+	case "":
+		return SubtitleStyle.Render("none")
+	case "READY":
+		return HealthyStyle.Render("●") + " " + formatAge(now.Sub(deployedAt))
+	case "ERROR":
+		return ErrorStyle.Render("failed")
+	case "BUILDING", "QUEUED", "INITIALIZING":
+		return WarningStyle.Render("building")
+	default:
+		return SubtitleStyle.Render(strings.ToLower(state))
+	}
+}
+
+// formatAge renders a duration compactly, e.g. "45s", "12m", "3h", "9d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 func truncate(value string, width int) string {
 	if width <= 0 {
 		return ""
@@ -667,6 +1060,46 @@ func truncate(value string, width int) string {
 	return string(runes[:width-3]) + "..."
 }
 
+// formatColumn renders a registered provider's metric, or "-" when the
+// product has no value for it.
+func formatColumn(c providers.Column, metrics *domain.Metrics) string {
+	if metrics == nil {
+		return "-"
+	}
+	if c.Format == providers.FormatText {
+		if label, ok := metrics.Labels[c.Key]; ok {
+			return label
+		}
+		return "-"
+	}
+
+	value, ok := metrics.Values[c.Key]
+	if !ok {
+		return "-"
+	}
+	switch c.Format {
+	case providers.FormatCurrency:
+		return formatCurrency(int64(math.Round(value)))
+	case providers.FormatPercent:
+		return fmt.Sprintf("%.1f%%", value)
+	case providers.FormatMillis:
+		return fmt.Sprintf("%dms", int64(math.Round(value)))
+	default:
+		if value == math.Trunc(value) {
+			return formatNumber(int64(value))
+		}
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+}
+
+// formatIssues renders unresolved Sentry issues, flagging new ones, e.g. "12 +3".
+func formatIssues(unresolved, newIssues int64) string {
+	if newIssues > 0 {
+		return fmt.Sprintf("%s +%s", formatNumber(unresolved), formatNumber(newIssues))
+	}
+	return formatNumber(unresolved)
+}
+
 func formatCurrency(cents int64) string {
 	return fmt.Sprintf("$%.2f", float64(cents)/100)
 }
@@ -727,13 +1160,60 @@ func renderSparkline(values []int64, width int, style lipgloss.Style) string {
 	return line.View()
 }
 
-// fetchMetrics returns a command that fetches all metrics concurrently.
-func (m *Model) fetchMetrics() tea.Cmd {
+// loadCached returns a command that reads the last stored metrics.
+func (m *Model) loadCached() tea.Cmd {
+	products := append([]domain.Product(nil), m.products...)
+	fetcher := m.fetcher
+	return func() tea.Msg {
+		metrics, err := fetcher.LoadCached(context.Background(), products)
+		return cachedMetricsMsg{metrics: metrics, err: err}
+	}
+}
+
+// loadUptime returns a command that computes uptime from the stored health
+// snapshots.
+func (m *Model) loadUptime() tea.Cmd {
+	products := append([]domain.Product(nil), m.products...)
+	fetcher := m.fetcher
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		uptime, err := fetcher.Uptime(context.Background(), products, time.Now())
+		return uptimeMsg{uptime: uptime, err: err}
+	}
+}
 
-		metrics := m.fetcher.FetchAll(ctx, m.products)
-		return metricsLoadedMsg{metrics: metrics}
+// startFetch marks every product in flight and returns a command that streams
+// per-product updates from the fetcher, one message at a time.
+func (m *Model) startFetch() tea.Cmd {
+	m.loading = true
+	m.inFlight = make(map[string]map[string]bool, len(m.products))
+	for _, p := range m.products {
+		m.inFlight[p.Name] = make(map[string]bool)
+	}
+
+	products := append([]domain.Product(nil), m.products...)
+	fetcher := m.fetcher
+	return func() tea.Msg {
+		updates := make(chan providers.Update, len(products))
+		go func() {
+			defer close(updates)
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			fetcher.FetchEach(ctx, products, func(u providers.Update) {
+				updates <- u
+			})
+		}()
+		return waitForUpdate(updates)()
+	}
+}
+
+// waitForUpdate returns a command that delivers the next streamed update, or
+// fetchDoneMsg once the stream closes.
+func waitForUpdate(updates <-chan providers.Update) tea.Cmd {
+	return func() tea.Msg {
+		u, ok := <-updates
+		if !ok {
+			return fetchDoneMsg{}
+		}
+		return productUpdateMsg{update: u, updates: updates}
 	}
 }
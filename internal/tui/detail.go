@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/report"
+)
+
+// The detail view lists up to detailRecentLimit checks from the last
+// detailHistory.
+const (
+	detailHistory     = 24 * time.Hour
+	detailRecentLimit = 10
+)
+
+type historyMsg struct {
+	product string
+	metrics []*domain.Metrics // oldest first
+	err     error
+}
+
+// openDetail shows the selected product and loads its recent history.
+func (m *Model) openDetail() tea.Cmd {
+	if m.selected < 0 || m.selected >= len(m.products) {
+		return nil
+	}
+	m.detail = m.products[m.selected].Name
+	m.history = nil
+	return m.loadHistory()
+}
+
+// loadHistory returns a command that reads the detail product's recent
+// snapshots from the store.
+func (m *Model) loadHistory() tea.Cmd {
+	name := m.detail
+	fetcher := m.fetcher
+	return func() tea.Msg {
+		metrics, err := fetcher.History(context.Background(), name, time.Now().Add(-detailHistory))
+		return historyMsg{product: name, metrics: metrics, err: err}
+	}
+}
+
+// detailView renders one product: health with its request phase breakdown,
+// certificate and domain expiry, and the latest checks from the store.
+func (m *Model) detailView() string {
+	idx := slices.IndexFunc(m.products, func(p domain.Product) bool { return p.Name == m.detail })
+	if idx < 0 {
+		return SubtitleStyle.Render("Product not found.")
+	}
+	product := m.products[idx]
+	metrics := m.metrics[product.Name]
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(strings.ToUpper(product.Name)))
+	if product.Domain != "" {
+		b.WriteString("  " + SubtitleStyle.Render(product.Domain))
+	}
+	b.WriteString("\n\n")
+
+	if metrics == nil {
+		b.WriteString(SubtitleStyle.Render("No metrics yet."))
+		b.WriteString("\n\n")
+		b.WriteString(HelpStyle.Render("esc back • r refresh • q quit"))
+		return b.String()
+	}
+
+	health := healthDot(metrics.HealthStatus) + " " + valueOr(metrics.HealthStatus, "unknown")
+	if metrics.HealthReason != "" {
+		health += SubtitleStyle.Render(" — " + metrics.HealthReason)
+	}
+	writeField(&b, "Health", health)
+	writeField(&b, "Latency", formatMillis(metrics.ResponseTime))
+	if windows := m.uptime[product.Name]; len(windows) > 0 {
+		parts := make([]string, 0, len(windows))
+		for _, u := range windows {
+			percent, ok := u.Percent()
+			cell := "-"
+			if ok {
+				cell = report.FormatUptime(&percent)
+			}
+			parts = append(parts, report.FormatWindow(u.Window)+" "+cell)
+		}
+		writeField(&b, "Uptime", strings.Join(parts, " • "))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(TableHeaderStyle.Render("REQUEST PHASES"))
+	b.WriteString("\n")
+	b.WriteString(m.phasesView(metrics))
+	b.WriteString("\n")
+
+	switch {
+	case metrics.CertError != "":
+		writeField(&b, "Certificate", ErrorStyle.Render(metrics.CertError))
+	case !metrics.CertExpiresAt.IsZero():
+		writeField(&b, "Certificate", expiryText(metrics.CertIssuer, metrics.CertExpiresAt, metrics.CertDaysLeft(), metrics.CertExpiring))
+	}
+	if expiresAt := metrics.DomainExpiresAt(); !expiresAt.IsZero() {
+		expiring := slices.Contains(metrics.Warnings(), domain.SignalDomainExpiring)
+		writeField(&b, "Domain", expiryText(metrics.DomainRegistrar(), expiresAt, metrics.DomainDaysLeft(), expiring))
+	}
+	for _, e := range metrics.Errors {
+		writeField(&b, "Error", ErrorStyle.Render(e))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(TableHeaderStyle.Render("RECENT CHECKS"))
+	b.WriteString("\n")
+	b.WriteString(m.recentChecksView())
+	b.WriteString("\n\n")
+	b.WriteString(HelpStyle.Render("esc back • r refresh • q quit"))
+	return b.String()
+}
+
+// phasesView draws one bar per request phase, scaled to the whole request.
+func (m *Model) phasesView(metrics *domain.Metrics) string {
+	phases := []struct {
+		name string
+		ms   int64
+	}{
+		{"DNS", metrics.DNSTime},
+		{"Connect", metrics.ConnectTime},
+		{"TLS", metrics.TLSTime},
+		{"TTFB", metrics.TTFB},
+		{"Download", metrics.DownloadTime},
+	}
+	total := max(metrics.ResponseTime, 1)
+	for _, p := range phases {
+		total = max(total, p.ms)
+	}
+	barWidth := 40
+	if m.width > 0 {
+		barWidth = max(10, min(barWidth, m.width-24))
+	}
+
+	lines := make([]string, 0, len(phases))
+	for _, p := range phases {
+		bar := strings.Repeat("█", int(p.ms*int64(barWidth)/total))
+		if bar == "" && p.ms > 0 {
+			bar = "▏"
+		}
+		lines = append(lines, fmt.Sprintf("  %-9s %7s  %s", p.name, formatMillis(p.ms), HealthyStyle.Render(bar)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// recentChecksView lists the latest stored checks, newest first.
+func (m *Model) recentChecksView() string {
+	if len(m.history) == 0 {
+		return SubtitleStyle.Render("  No checks stored in the last 24h.")
+	}
+
+	lines := []string{SubtitleStyle.Render(fmt.Sprintf("  %-8s %-10s %7s %7s %7s %7s %7s %8s",
+		"TIME", "STATUS", "TOTAL", "DNS", "CONNECT", "TLS", "TTFB", "DOWNLOAD"))}
+	for i := len(m.history) - 1; i >= 0 && len(lines) <= detailRecentLimit; i-- {
+		h := m.history[i]
+		lines = append(lines, fmt.Sprintf("  %-8s %s %-8s %7s %7s %7s %7s %7s %8s",
+			h.Timestamp.Local().Format("15:04:05"),
+			healthDot(h.HealthStatus),
+			valueOr(h.HealthStatus, "-"),
+			formatMillis(h.ResponseTime),
+			formatMillis(h.DNSTime),
+			formatMillis(h.ConnectTime),
+			formatMillis(h.TLSTime),
+			formatMillis(h.TTFB),
+			formatMillis(h.DownloadTime),
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeField(b *strings.Builder, label, value string) {
+	b.WriteString(lipgloss.NewStyle().Width(13).Render(label))
+	b.WriteString(value)
+	b.WriteString("\n")
+}
+
+// expiryText renders who issued or registered something and when it
+// expires, warning when that is soon.
+func expiryText(by string, expiresAt time.Time, daysLeft int, warn bool) string {
+	text := fmt.Sprintf("expires %s (%dd)", expiresAt.Local().Format("2006-01-02"), daysLeft)
+	if warn {
+		text = WarningStyle.Render(text)
+	}
+	if by == "" {
+		return text
+	}
+	return by + ", " + text
+}
+
+func formatMillis(ms int64) string {
+	return fmt.Sprintf("%dms", ms)
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
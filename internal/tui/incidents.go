@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/report"
+)
+
+// incidentsHistory is how far back the incidents pane looks.
+const incidentsHistory = 30 * 24 * time.Hour
+
+type incidentsMsg struct {
+	incidents []domain.Incident // newest first
+	err       error
+}
+
+// openIncidents shows the incidents pane and loads its contents.
+func (m *Model) openIncidents() tea.Cmd {
+	m.detail = ""
+	m.showRevenue = false
+	m.showIncidents = true
+	return m.loadIncidents()
+}
+
+// loadIncidents returns a command that reads recent incidents from the store.
+func (m *Model) loadIncidents() tea.Cmd {
+	products := append([]domain.Product(nil), m.products...)
+	fetcher := m.fetcher
+	return func() tea.Msg {
+		incidents, err := fetcher.Incidents(context.Background(), products, time.Now().Add(-incidentsHistory))
+		return incidentsMsg{incidents: incidents, err: err}
+	}
+}
+
+// reloadIncidents refreshes the incidents pane while it is open.
+func (m *Model) reloadIncidents() tea.Cmd {
+	if !m.showIncidents {
+		return nil
+	}
+	return m.loadIncidents()
+}
+
+// incidentsView lists every product's incidents from the last 30 days,
+// ongoing ones highlighted, as many as fit the screen.
+func (m *Model) incidentsView() string {
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("INCIDENTS"))
+	b.WriteString("  " + SubtitleStyle.Render("last 30 days"))
+	b.WriteString("\n\n")
+
+	if len(m.incidents) == 0 {
+		b.WriteString(SubtitleStyle.Render("No incidents recorded."))
+	} else {
+		limit := len(m.incidents)
+		if m.height > 0 {
+			limit = min(limit, max(1, m.height-7))
+		}
+		reasonWidth := 40
+		if m.width > 0 {
+			reasonWidth = max(10, m.width-70)
+		}
+
+		now := time.Now()
+		lines := []string{SubtitleStyle.Render(fmt.Sprintf("  %-16s %-16s %-16s %9s %-10s %s",
+			"PRODUCT", "STARTED", "ENDED", "DURATION", "STATUS", "REASON"))}
+		for _, incident := range m.incidents[:limit] {
+			ended := WarningStyle.Render(fmt.Sprintf("%-16s", "ongoing"))
+			if !incident.Ongoing() {
+				ended = fmt.Sprintf("%-16s", incident.EndedAt.Local().Format("2006-01-02 15:04"))
+			}
+			lines = append(lines, fmt.Sprintf("  %-16s %-16s %s %9s %s %-8s %s",
+				truncate(incident.ProductName, 16),
+				incident.StartedAt.Local().Format("2006-01-02 15:04"),
+				ended,
+				report.FormatDuration(incident.Duration(now)),
+				healthDot(incident.Status),
+				incident.Status,
+				truncate(incident.Reason, reasonWidth),
+			))
+		}
+		if limit < len(m.incidents) {
+			lines = append(lines, SubtitleStyle.Render(fmt.Sprintf("  … %d more; run overmind incidents", len(m.incidents)-limit)))
+		}
+		b.WriteString(strings.Join(lines, "\n"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(HelpStyle.Render("esc back • r refresh • q quit"))
+	return b.String()
+}
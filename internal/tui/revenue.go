@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/phaedrus/overmind/internal/domain"
+)
+
+// revenueDays is how many UTC days, including today, the revenue view covers.
+const revenueDays = 30
+
+type revenueMsg struct {
+	movements map[string]domain.MRRMovement
+	err       error
+}
+
+// openRevenue shows the revenue view and loads its contents.
+func (m *Model) openRevenue() tea.Cmd {
+	m.detail = ""
+	m.showIncidents = false
+	m.showRevenue = true
+	return m.loadRevenue()
+}
+
+// loadRevenue returns a command that sums MRR movements from the store.
+func (m *Model) loadRevenue() tea.Cmd {
+	products := append([]domain.Product(nil), m.products...)
+	fetcher := m.fetcher
+	return func() tea.Msg {
+		now := time.Now()
+		movements, err := fetcher.MRRMovement(context.Background(), products, now.AddDate(0, 0, -(revenueDays-1)), now)
+		return revenueMsg{movements: movements, err: err}
+	}
+}
+
+// reloadRevenue refreshes the revenue view while it is open.
+func (m *Model) reloadRevenue() tea.Cmd {
+	if !m.showRevenue {
+		return nil
+	}
+	return m.loadRevenue()
+}
+
+// revenueView shows how MRR moved across all products as a waterfall, then
+// each product's movements.
+func (m *Model) revenueView() string {
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("REVENUE"))
+	b.WriteString("  " + SubtitleStyle.Render(fmt.Sprintf("last %d days", revenueDays)))
+	b.WriteString("\n\n")
+
+	var (
+		total domain.MRRMovement
+		rows  []string
+	)
+	for _, p := range m.products {
+		mv, ok := m.revenue[p.Name]
+		if !ok {
+			continue
+		}
+		total.StartMRR += mv.StartMRR
+		total.EndMRR += mv.EndMRR
+		total.New += mv.New
+		total.Expansion += mv.Expansion
+		total.Contraction += mv.Contraction
+		total.Churned += mv.Churned
+		total.NewSubscriptions += mv.NewSubscriptions
+		total.CanceledSubscriptions += mv.CanceledSubscriptions
+		rows = append(rows, fmt.Sprintf("  %-16s %11s %11s %11s %11s %11s %11s %6s %6s",
+			truncate(p.Name, 16),
+			formatCurrency(mv.StartMRR),
+			formatSignedCurrency(mv.New),
+			formatSignedCurrency(mv.Expansion),
+			formatSignedCurrency(-mv.Contraction),
+			formatSignedCurrency(-mv.Churned),
+			formatCurrency(mv.EndMRR),
+			fmt.Sprintf("+%d", mv.NewSubscriptions),
+			fmt.Sprintf("-%d", mv.CanceledSubscriptions),
+		))
+	}
+
+	if len(rows) == 0 {
+		b.WriteString(SubtitleStyle.Render("No Stripe revenue stored yet."))
+	} else {
+		b.WriteString(m.waterfallView(total))
+		b.WriteString("\n\n")
+		b.WriteString(SubtitleStyle.Render(fmt.Sprintf("  %-16s %11s %11s %11s %11s %11s %11s %6s %6s",
+			"PRODUCT", "START", "NEW", "EXPANSION", "CONTRACTION", "CHURNED", "END", "SUBS", "CANCEL")))
+		b.WriteString("\n")
+		b.WriteString(strings.Join(rows, "\n"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(HelpStyle.Render("esc back • r refresh • q quit"))
+	return b.String()
+}
+
+// waterfallView draws MRR from start to end, one bar per movement, each
+// starting where the previous one left off.
+func (m *Model) waterfallView(mv domain.MRRMovement) string {
+	type step struct {
+		label string
+		delta int64
+		total bool // drawn from zero rather than from the running MRR
+	}
+	steps := []step{
+		{label: "Start", delta: mv.StartMRR, total: true},
+		{label: "New", delta: mv.New},
+		{label: "Expansion", delta: mv.Expansion},
+		{label: "Contraction", delta: -mv.Contraction},
+		{label: "Churned", delta: -mv.Churned},
+	}
+	if other := mv.Other(); other != 0 {
+		steps = append(steps, step{label: "Other", delta: other})
+	}
+	steps = append(steps, step{label: "End", delta: mv.EndMRR, total: true})
+
+	// Scale to the highest point the running MRR reaches.
+	peak, running := int64(1), int64(0)
+	for _, s := range steps {
+		if s.total {
+			running = s.delta
+		} else {
+			running += s.delta
+		}
+		peak = max(peak, running)
+	}
+	barWidth := 40
+	if m.width > 0 {
+		barWidth = max(10, min(60, m.width-34))
+	}
+	scale := func(cents int64) int {
+		return int(max(0, cents) * int64(barWidth) / peak)
+	}
+
+	lines := make([]string, 0, len(steps))
+	running = 0
+	for _, s := range steps {
+		lo, hi := running, running+s.delta
+		amount := formatSignedCurrency(s.delta)
+		style := HealthyStyle
+		switch {
+		case s.total:
+			lo, hi = 0, s.delta
+			amount = formatCurrency(s.delta)
+			style = lipgloss.NewStyle()
+		case s.delta < 0:
+			lo, hi = hi, lo
+			style = ErrorStyle
+		}
+		if s.total {
+			running = s.delta
+		} else {
+			running += s.delta
+		}
+
+		width := scale(hi) - scale(lo)
+		if width == 0 && s.delta != 0 {
+			width = 1
+		}
+		bar := strings.Repeat(" ", scale(lo)) + style.Render(strings.Repeat("█", width))
+		lines = append(lines, fmt.Sprintf("  %-12s %12s  %s", s.label, amount, bar))
+	}
+	lines = append(lines, SubtitleStyle.Render(fmt.Sprintf("  %d new • %d canceled subscriptions", mv.NewSubscriptions, mv.CanceledSubscriptions)))
+	return strings.Join(lines, "\n")
+}
+
+func formatSignedCurrency(cents int64) string {
+	if cents < 0 {
+		return "-" + formatCurrency(-cents)
+	}
+	return "+" + formatCurrency(cents)
+}
@@ -1,38 +1,139 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"sort"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/phaedrus/overmind/internal/config"
+	"github.com/phaedrus/overmind/internal/domain"
 	"github.com/phaedrus/overmind/internal/providers"
 	"github.com/phaedrus/overmind/internal/store"
 	"github.com/phaedrus/overmind/internal/tui"
 )
 
+const usage = `Usage: overmind [command] [flags]
+
+Commands:
+  (none)      Launch the interactive dashboard (-offline: cached metrics only)
+  status      Fetch metrics once and print them (table, json or csv)
+  daemon      Snapshot metrics into the store on a schedule
+  backfill    Load daily traffic history from PostHog into the store
+  uptime      Print uptime over 24h, 7d and 30d from stored health checks
+  incidents   List outages and degradations recorded from health checks
+  statuspage  Render a static HTML status page from stored health checks
+
+Run "overmind <command> -h" for command flags.
+`
+
+// exitError carries a specific process exit code out of run.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	if err := run(os.Args[1:]); err != nil {
+		code := 1
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+		}
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		} else {
+			code = 0
+		}
+		os.Exit(code)
 	}
 }
 
-func run() (err error) {
+func run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "status":
+			return runStatus(args[1:])
+		case "daemon":
+			return runDaemon(args[1:])
+		case "backfill":
+			return runBackfill(args[1:])
+		case "uptime":
+			return runUptime(args[1:])
+		case "incidents":
+			return runIncidents(args[1:])
+		case "statuspage":
+			return runStatusPage(args[1:])
+		case "help", "-h", "--help":
+			fmt.Fprint(os.Stdout, usage)
+			return nil
+		}
+	}
+	return runDashboard(args)
+}
+
+func runDashboard(args []string) error {
+	fs := flag.NewFlagSet("overmind", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage) }
+	configPath := fs.String("config", "", "path to config file (default ~/.overmind/config.yaml)")
+	offline := fs.Bool("offline", false, "show cached metrics only; never touch the network")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+
+	return withApp(*configPath, func(a *app) error {
+		// Create TUI model.
+		model := tui.New(a.products, a.fetcher, tui.Options{Offline: *offline})
+
+		// Run program.
+		prog := tea.NewProgram(model, tea.WithAltScreen())
+		if _, err := prog.Run(); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// app holds the dependencies shared by every command.
+type app struct {
+	cfg      *config.Config
+	store    *store.Store
+	fetcher  *providers.MetricsFetcher
+	products []domain.Product
+}
+
+// withApp loads config, opens the store and wires the fetcher, then calls fn.
+// The store is always closed afterwards.
+func withApp(configPath string, fn func(a *app) error) (err error) {
 	// Load config.
-	cfg, err := config.Load("")
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
 	// Initialize providers.
-	p := providers.New(
-		cfg.Credentials.Stripe.SecretKey,
-		cfg.Credentials.PostHog.APIKey,
-		cfg.Credentials.PostHog.ProjectID,
-		cfg.Credentials.PostHog.Host,
-	)
+	p := providers.New(providers.Credentials{
+		StripeSecretKey:  cfg.Credentials.Stripe.SecretKey,
+		PostHogAPIKey:    cfg.Credentials.PostHog.APIKey,
+		PostHogProjectID: cfg.Credentials.PostHog.ProjectID,
+		PostHogHost:      cfg.Credentials.PostHog.Host,
+		SentryAuthToken:  cfg.Credentials.Sentry.AuthToken,
+		SentryOrg:        cfg.Credentials.Sentry.Org,
+		SentryHost:       cfg.Credentials.Sentry.Host,
+		VercelToken:      cfg.Credentials.Vercel.Token,
+		VercelTeamID:     cfg.Credentials.Vercel.TeamID,
+		GitHubToken:      cfg.Credentials.GitHub.Token,
+	})
 
 	// Initialize store.
 	s, err := store.Open("")
@@ -45,17 +146,61 @@ func run() (err error) {
 		}
 	}()
 
-	fetcher := p.NewMetricsFetcher(s)
-
-	// Create TUI model.
 	products := cfg.ToProducts()
-	model := tui.New(products, fetcher)
+	registry := p.Registry(s)
+	if err := registerPlugins(registry, cfg, products); err != nil {
+		return fmt.Errorf("loading plugins: %w", err)
+	}
+	if err := registry.Validate(products); err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
 
-	// Run program.
-	prog := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := prog.Run(); err != nil {
-		return err
+	fetcher := providers.NewMetricsFetcher(registry, s)
+	fetcher.SetSnapshotInterval(cfg.Daemon.Every())
+
+	return fn(&app{
+		cfg:      cfg,
+		store:    s,
+		fetcher:  fetcher,
+		products: products,
+	})
+}
+
+// registerPlugins adds an external-executable provider for every provider
+// name a product uses that is configured as a plugin or isn't built in, found
+// as overmind-provider-<name> on PATH unless the plugin names a command.
+// Plugins no product uses are never looked up, so a missing binary for one
+// of them doesn't stop startup.
+func registerPlugins(registry *providers.Registry, cfg *config.Config, products []domain.Product) error {
+	names := make(map[string]bool)
+	for _, p := range products {
+		for name := range p.Providers {
+			_, plugin := cfg.Plugins[name]
+			if _, builtin := registry.Lookup(name); plugin || !builtin {
+				names[name] = true
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
 	}
+	sort.Strings(sorted)
 
+	for _, name := range sorted {
+		plugin := cfg.Plugins[name]
+		spec := providers.ExecSpec{Command: plugin.Command, Timeout: plugin.Timeout}
+		for _, c := range plugin.Columns {
+			spec.Columns = append(spec.Columns, providers.Column{Key: c.Key, Title: c.Title, Format: c.Format, Width: c.Width})
+		}
+		provider, err := providers.NewExecProvider(name, spec)
+		if err != nil {
+			return err
+		}
+		if err := registry.Register(provider); err != nil {
+			return err
+		}
+	}
 	return nil
 }
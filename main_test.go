@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phaedrus/overmind/internal/config"
+	"github.com/phaedrus/overmind/internal/domain"
+	"github.com/phaedrus/overmind/internal/providers"
+)
+
+func TestRegisterPlugins(t *testing.T) {
+	cfg := &config.Config{Plugins: map[string]config.PluginConfig{
+		"missing": {Command: []string{"overmind-test-no-such-binary"}},
+	}}
+
+	tests := []struct {
+		name     string
+		products []domain.Product
+		wantErr  string
+	}{
+		{
+			name:     "unused plugin is not looked up",
+			products: []domain.Product{{Name: "App"}},
+		},
+		{
+			name:     "used plugin must exist",
+			products: []domain.Product{{Name: "App", Providers: map[string]domain.ProviderConfig{"missing": {}}}},
+			wantErr:  `plugin "missing"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registerPlugins(providers.NewRegistry(), cfg, tt.products)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("registerPlugins() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("registerPlugins() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/report"
+)
+
+// Exit codes for non-interactive commands.
+const (
+	exitFetchErrors = 2 // metrics were printed but at least one provider failed
+)
+
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file (default ~/.overmind/config.yaml)")
+	format := fs.String("format", string(report.FormatTable), "output format: table, json or csv")
+	timeout := fs.Duration("timeout", 30*time.Second, "maximum time to wait for all providers")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: overmind status [flags]\n\nFetch metrics once and print them. Exits 2 if any provider failed.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	outFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	return withApp(*configPath, func(a *app) error {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		metrics := a.fetcher.FetchAll(ctx, a.products)
+		rows := report.Rows(a.products, metrics)
+		if err := report.Write(os.Stdout, outFormat, rows); err != nil {
+			return err
+		}
+
+		if !report.HasErrors(rows) {
+			return nil
+		}
+		if outFormat == report.FormatTable {
+			for _, row := range rows {
+				for _, msg := range row.Errors {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", row.Name, msg)
+				}
+			}
+		}
+		return &exitError{code: exitFetchErrors, err: errors.New("one or more providers failed")}
+	})
+}
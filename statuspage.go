@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/statuspage"
+)
+
+func runStatusPage(args []string) error {
+	fs := flag.NewFlagSet("statuspage", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file (default ~/.overmind/config.yaml)")
+	out := fs.String("out", "status", "directory to write the site into")
+	title := fs.String("title", "Status", "page title")
+	days := fs.Int("days", 90, "number of UTC days of uptime bars, including today")
+	incidentDays := fs.Int("incident-days", 30, "list incidents ongoing at any point in the last N days")
+	showReasons := fs.Bool("show-reasons", false, "list health check reasons, which can name internal hosts, on incidents")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: overmind statuspage [flags]\n\nRender a static HTML status site from stored health checks and incidents:\nindex.html for every product plus <product>/index.html for each one.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return fmt.Errorf("days must be positive, got %d", *days)
+	}
+	if *incidentDays <= 0 {
+		return fmt.Errorf("incident-days must be positive, got %d", *incidentDays)
+	}
+
+	return withApp(*configPath, func(a *app) error {
+		ctx := context.Background()
+		now := time.Now()
+
+		latest, err := a.fetcher.LoadCached(ctx, a.products)
+		if err != nil {
+			return err
+		}
+		uptime, err := a.fetcher.DailyUptime(ctx, a.products, now, *days)
+		if err != nil {
+			return err
+		}
+		incidents, err := a.fetcher.Incidents(ctx, a.products, now.AddDate(0, 0, -*incidentDays))
+		if err != nil {
+			return err
+		}
+
+		site := statuspage.Site{Title: *title, GeneratedAt: now, ShowReasons: *showReasons}
+		for _, p := range a.products {
+			product := statuspage.Product{Name: p.Name, Domain: p.Domain, Days: uptime[p.Name]}
+			// A health check older than the fetcher's status age is shown
+			// as unknown rather than repeated as the current status.
+			if m := latest[p.Name]; m != nil && now.Sub(m.Timestamp) <= a.fetcher.StatusMaxAge() {
+				product.Status = m.HealthStatus
+			}
+			for _, incident := range incidents {
+				if incident.ProductName == p.Name {
+					product.Incidents = append(product.Incidents, incident)
+				}
+			}
+			site.Products = append(site.Products, product)
+		}
+
+		if err := statuspage.Write(*out, site); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Wrote status page for %d products to %s\n", len(site.Products), *out)
+		return nil
+	})
+}
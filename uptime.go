@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/phaedrus/overmind/internal/report"
+)
+
+func runUptime(args []string) error {
+	fs := flag.NewFlagSet("uptime", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to config file (default ~/.overmind/config.yaml)")
+	format := fs.String("format", string(report.FormatTable), "output format: table, json or csv")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: overmind uptime [flags]\n\nPrint each product's uptime over the last 24h, 7d and 30d from stored health\nchecks. Run the daemon to keep the history complete.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	outFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	return withApp(*configPath, func(a *app) error {
+		uptime, err := a.fetcher.Uptime(context.Background(), a.products, time.Now())
+		if err != nil {
+			return err
+		}
+		return report.WriteUptime(os.Stdout, outFormat, report.UptimeRows(a.products, uptime))
+	})
+}